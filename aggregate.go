@@ -40,3 +40,14 @@ type AggregateClause struct {
 func (c AggregateClause) Accept(context Context) string {
 	return context.Compiler().VisitAggregate(context, c)
 }
+
+// Over turns the aggregate into a window function call over the given window
+// Count(col).Over(Window().PartitionBy(usersTable.C("group_id")))
+func (c AggregateClause) Over(window WindowClause) OverClause {
+	return OverClause{c, window}
+}
+
+// Label wraps the Label(name string, clause Clause)
+func (c AggregateClause) Label(name string) LabelledClause {
+	return Label(name, c)
+}
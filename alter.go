@@ -0,0 +1,472 @@
+package qb
+
+import "strings"
+
+// AlterOp is the kind of change made by an ALTER TABLE action
+type AlterOp int
+
+// The ALTER TABLE actions
+const (
+	AlterAddColumn AlterOp = iota
+	AlterDropColumn
+	AlterRenameColumn
+	AlterColumnType
+	AlterSetNotNull
+	AlterDropNotNull
+	AlterSetDefault
+	AlterDropDefault
+	AlterAddConstraint
+	AlterDropConstraint
+	AlterRenameTable
+	AlterAddIndex
+	AlterDropIndex
+)
+
+// AlterTable generates an ALTER TABLE statement given the current definition
+// of the table. The definition is needed by the dialects that redefine whole
+// columns, or rebuild the table (sqlite), to alter it
+// AlterTable(users).AddColumn(Column("age", Int())).RenameColumn("name", "full_name")
+func AlterTable(table TableElem) AlterTableStmt {
+	table.UniqueKeyConstraints = table.uniqueKeys()
+	return AlterTableStmt{Table: table}
+}
+
+// AlterTableStmt is the base struct for any alter table statements
+// The actions are compiled in as few sql statements as the dialect allows
+type AlterTableStmt struct {
+	Table   TableElem
+	Actions []AlterAction
+
+	// errors are the invalid actions, reported when the statement is built
+	errors []error
+}
+
+// AlterAction is an action of an ALTER TABLE statement
+type AlterAction struct {
+	Op AlterOp
+	// Name is the name of the column, constraint or index the action applies to
+	Name string
+	// NewName is the new name of a renamed column or table
+	NewName    string
+	Column     ColumnElem
+	Type       TypeElem
+	Default    interface{}
+	Constraint TableSQLClause
+	Index      IndexElem
+}
+
+// add appends an action to the statement. If the action is invalid for the
+// table as altered by the previous actions, an error is recorded
+func (s AlterTableStmt) add(action AlterAction) AlterTableStmt {
+	table := s.Altered()
+	switch action.Op {
+	case AlterAddColumn:
+		if table.Has(action.Column.Name) {
+			s.errors = append(s.errors, CompileError("Column '%s' already exists in table %s", action.Column.Name, table.Name))
+		}
+	case AlterRenameColumn:
+		if table.Has(action.NewName) {
+			s.errors = append(s.errors, CompileError("Column '%s' already exists in table %s", action.NewName, table.Name))
+		}
+		fallthrough
+	case AlterDropColumn, AlterColumnType, AlterSetNotNull, AlterDropNotNull, AlterSetDefault, AlterDropDefault:
+		if !table.Has(action.Name) {
+			s.errors = append(s.errors, CompileError("No such column '%s' in table %s", action.Name, table.Name))
+		}
+	case AlterAddConstraint:
+		switch action.Constraint.(type) {
+		case PrimaryKeyConstraint, ForeignKeyConstraint, UniqueKeyConstraint, CheckConstraint:
+		default:
+			s.errors = append(s.errors, CompileError("Cannot add %T to table %s", action.Constraint, table.Name))
+		}
+	}
+	s.Actions = append(s.Actions[:len(s.Actions):len(s.Actions)], action)
+	return s
+}
+
+// AddColumn adds a column to the table
+func (s AlterTableStmt) AddColumn(column ColumnElem) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterAddColumn, Name: column.Name, Column: column})
+}
+
+// DropColumn drops a column of the table
+func (s AlterTableStmt) DropColumn(name string) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterDropColumn, Name: name})
+}
+
+// RenameColumn renames a column of the table
+func (s AlterTableStmt) RenameColumn(name string, newName string) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterRenameColumn, Name: name, NewName: newName})
+}
+
+// AlterColumnType changes the type of a column of the table
+func (s AlterTableStmt) AlterColumnType(name string, t TypeElem) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterColumnType, Name: name, Type: t})
+}
+
+// SetNotNull adds a not null constraint to a column of the table
+func (s AlterTableStmt) SetNotNull(name string) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterSetNotNull, Name: name})
+}
+
+// DropNotNull removes the not null constraint of a column of the table
+func (s AlterTableStmt) DropNotNull(name string) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterDropNotNull, Name: name})
+}
+
+// SetDefault sets the default value of a column of the table
+func (s AlterTableStmt) SetDefault(name string, value interface{}) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterSetDefault, Name: name, Default: value})
+}
+
+// DropDefault removes the default value of a column of the table
+func (s AlterTableStmt) DropDefault(name string) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterDropDefault, Name: name})
+}
+
+// AddConstraint adds a PrimaryKey, ForeignKey, UniqueKey or Check constraint
+// to the table
+func (s AlterTableStmt) AddConstraint(constraint TableSQLClause) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterAddConstraint, Constraint: constraint})
+}
+
+// DropConstraint drops a named constraint of the table
+func (s AlterTableStmt) DropConstraint(name string) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterDropConstraint, Name: name})
+}
+
+// RenameTo renames the table
+func (s AlterTableStmt) RenameTo(name string) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterRenameTable, NewName: name})
+}
+
+// AddIndex creates an index on the table. The table of the index is set to
+// the altered table
+func (s AlterTableStmt) AddIndex(index IndexElem) AlterTableStmt {
+	index = index.table(s.Table.Name)
+	return s.add(AlterAction{Op: AlterAddIndex, Name: index.Name, Index: index})
+}
+
+// DropIndex drops an index of the table
+func (s AlterTableStmt) DropIndex(name string) AlterTableStmt {
+	return s.add(AlterAction{Op: AlterDropIndex, Name: name})
+}
+
+// Altered returns the definition of the table once altered by all the
+// actions of the statement
+func (s AlterTableStmt) Altered() TableElem {
+	table := s.Table
+	for _, action := range s.Actions {
+		table = action.Apply(table)
+	}
+	return table
+}
+
+// Accept calls the compiler VisitAlterTable method
+func (s AlterTableStmt) Accept(context Context) string {
+	return context.Compiler().VisitAlterTable(context, s)
+}
+
+// Build generates a statement out of AlterTableStmt object
+// The statement holds the sql statements of the actions, separated by
+// semicolons. Engine.Exec and Tx.Exec execute them one by one, see Statements
+func (s AlterTableStmt) Build(dialect Dialect) *Stmt {
+	context := NewCompilerContext(dialect)
+	statement := Statement()
+	statement.AddError(s.Table.errors...)
+	statement.AddError(s.errors...)
+	statement.AddSQLClause(s.Accept(context))
+	statement.AddBinding(context.Binds()...)
+	statement.AddError(context.Errors()...)
+
+	return statement
+}
+
+// Statements generates the sql statements of the actions, to be executed in
+// order. The error of the first invalid action is returned, if any
+func (s AlterTableStmt) Statements(dialect Dialect) ([]*Stmt, error) {
+	context := NewCompilerContext(dialect)
+	sqls := context.Compiler().CompileAlterTable(context, s)
+	errs := append(append(append([]error{}, s.Table.errors...), s.errors...), context.Errors()...)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	statements := []*Stmt{}
+	for _, sql := range sqls {
+		statement := Statement()
+		statement.AddSQLClause(sql)
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// Apply returns the definition of the table once altered by the action
+// The given table is left untouched
+func (a AlterAction) Apply(table TableElem) TableElem {
+	table = table.clone()
+	switch a.Op {
+	case AlterAddColumn:
+		col := a.Column
+		col.Table = table.Name
+		table.columnNames = append(table.columnNames, col.Name)
+		table.Columns[col.Name] = col
+	case AlterDropColumn:
+		delete(table.Columns, a.Name)
+		table.columnNames = removeName(table.columnNames, a.Name)
+		indices := []IndexElem{}
+		for _, index := range table.Indices {
+			if !hasName(index.Columns, a.Name) {
+				indices = append(indices, index)
+			}
+		}
+		table.Indices = indices
+		fkeys := []ForeignKeyConstraint{}
+		for _, fkey := range table.ForeignKeyConstraints.FKeys {
+			if !hasName(fkey.Cols, a.Name) {
+				fkeys = append(fkeys, fkey)
+			}
+		}
+		table.ForeignKeyConstraints.FKeys = fkeys
+		uniques := []UniqueKeyConstraint{}
+		for _, unique := range table.UniqueKeyConstraints {
+			if !hasName(unique.cols, a.Name) {
+				uniques = append(uniques, unique)
+			}
+		}
+		table.UniqueKeyConstraints = uniques
+		table = table.dropChecks(a.Name)
+	case AlterRenameColumn:
+		col, ok := table.Columns[a.Name]
+		if !ok {
+			break
+		}
+		delete(table.Columns, a.Name)
+		col.Name = a.NewName
+		table.Columns[a.NewName] = col
+		table.columnNames = renameName(table.columnNames, a.Name, a.NewName)
+		table.PrimaryKeyConstraint.Columns = renameName(table.PrimaryKeyConstraint.Columns, a.Name, a.NewName)
+		for i := range table.Indices {
+			table.Indices[i].Columns = renameName(table.Indices[i].Columns, a.Name, a.NewName)
+			table.Indices[i].Descending = renameName(table.Indices[i].Descending, a.Name, a.NewName)
+		}
+		for i := range table.ForeignKeyConstraints.FKeys {
+			table.ForeignKeyConstraints.FKeys[i].Cols = renameName(table.ForeignKeyConstraints.FKeys[i].Cols, a.Name, a.NewName)
+		}
+		for i := range table.UniqueKeyConstraints {
+			table.UniqueKeyConstraints[i].cols = renameName(table.UniqueKeyConstraints[i].cols, a.Name, a.NewName)
+		}
+		table = table.renameChecks(a.Name, a.NewName)
+	case AlterColumnType, AlterSetNotNull, AlterDropNotNull, AlterSetDefault, AlterDropDefault:
+		col, ok := table.Columns[a.Name]
+		if !ok {
+			break
+		}
+		table.Columns[a.Name] = a.alterColumn(col)
+	case AlterAddConstraint:
+		switch constraint := a.Constraint.(type) {
+		case PrimaryKeyConstraint:
+			table.PrimaryKeyConstraint = constraint
+			for _, name := range constraint.Columns {
+				if col, ok := table.Columns[name]; ok {
+					table.Columns[name] = col.PrimaryKey()
+				}
+			}
+			if len(constraint.Columns) == 1 && constraint.name == "" {
+				if col, ok := table.Columns[constraint.Columns[0]]; ok {
+					table.Columns[col.Name] = col.inlinePrimaryKey()
+				}
+			}
+		case ForeignKeyConstraint:
+			table.ForeignKeyConstraints.FKeys = append(table.ForeignKeyConstraints.FKeys, constraint)
+		case UniqueKeyConstraint:
+			table.UniqueKeyConstraints = append(table.UniqueKeyConstraints, constraint.Table(table.Name))
+		case CheckConstraint:
+			constraint.table = table.Name
+			table.CheckConstraints = append(table.CheckConstraints, constraint)
+		}
+	case AlterDropConstraint:
+		if table.PrimaryKeyConstraint.name == a.Name {
+			for _, name := range table.PrimaryKeyConstraint.Columns {
+				if col, ok := table.Columns[name]; ok {
+					col.Options.PrimaryKey = false
+					col.Options.InlinePrimaryKey = false
+					table.Columns[name] = col
+				}
+			}
+			table.PrimaryKeyConstraint = PrimaryKeyConstraint{}
+		}
+		uniques := []UniqueKeyConstraint{}
+		for _, unique := range table.UniqueKeyConstraints {
+			if unique.name != a.Name {
+				uniques = append(uniques, unique)
+			}
+		}
+		table.UniqueKeyConstraints = uniques
+		checks := []CheckConstraint{}
+		for _, check := range table.CheckConstraints {
+			if check.name != a.Name {
+				checks = append(checks, check)
+			}
+		}
+		table.CheckConstraints = checks
+		fkeys := []ForeignKeyConstraint{}
+		for _, fkey := range table.ForeignKeyConstraints.FKeys {
+			if fkey.name != a.Name {
+				fkeys = append(fkeys, fkey)
+			}
+		}
+		table.ForeignKeyConstraints.FKeys = fkeys
+	case AlterRenameTable:
+		table.Name = a.NewName
+		for i := range table.CheckConstraints {
+			table.CheckConstraints[i].table = a.NewName
+		}
+		for name, col := range table.Columns {
+			col.Table = a.NewName
+			table.Columns[name] = col
+		}
+		for i := range table.Indices {
+			table.Indices[i].Table = a.NewName
+		}
+	case AlterAddIndex:
+		table.Indices = append(table.Indices, a.Index.table(table.Name))
+	case AlterDropIndex:
+		indices := []IndexElem{}
+		for _, index := range table.Indices {
+			if index.Name != a.Name {
+				indices = append(indices, index)
+			}
+		}
+		table.Indices = indices
+	}
+	table.UniqueKeyConstraint = UniqueKeyConstraint{}
+	if len(table.UniqueKeyConstraints) > 0 {
+		table.UniqueKeyConstraint = table.UniqueKeyConstraints[len(table.UniqueKeyConstraints)-1]
+	}
+	return table
+}
+
+// alterColumn returns the column changed by a column altering action
+func (a AlterAction) alterColumn(col ColumnElem) ColumnElem {
+	constraints := []ConstraintElem{}
+	for _, constraint := range col.Constraints {
+		switch {
+		case a.Op == AlterSetNotNull && (constraint.Name == "NULL" || constraint.Name == "NOT NULL"),
+			a.Op == AlterDropNotNull && constraint.Name == "NOT NULL",
+			(a.Op == AlterSetDefault || a.Op == AlterDropDefault) && strings.HasPrefix(constraint.Name, "DEFAULT "):
+			continue
+		}
+		constraints = append(constraints, constraint)
+	}
+	col.Constraints = constraints
+
+	switch a.Op {
+	case AlterColumnType:
+		col.Type = a.Type
+	case AlterSetNotNull:
+		col = col.NotNull()
+	case AlterSetDefault:
+		col = col.Default(a.Default)
+	}
+	return col
+}
+
+// dropChecks removes the table and column check constraints that reference
+// the dropped column
+func (t TableElem) dropChecks(name string) TableElem {
+	checks := []CheckConstraint{}
+	for _, check := range t.CheckConstraints {
+		if !hasName(expressionColumns(t.Name, check.clause, check.renames), name) {
+			checks = append(checks, check)
+		}
+	}
+	t.CheckConstraints = checks
+	for colName, col := range t.Columns {
+		constraints := []ConstraintElem{}
+		for _, constraint := range col.Constraints {
+			if constraint.check == nil || !hasName(expressionColumns(t.Name, constraint.check, constraint.renames), name) {
+				constraints = append(constraints, constraint)
+			}
+		}
+		col.Constraints = constraints
+		t.Columns[colName] = col
+	}
+	return t
+}
+
+// renameChecks makes the table and column check constraints reference the
+// renamed column by its new name
+func (t TableElem) renameChecks(name string, newName string) TableElem {
+	for i, check := range t.CheckConstraints {
+		t.CheckConstraints[i].renames = renameColumn(check.renames, name, newName)
+	}
+	for colName, col := range t.Columns {
+		constraints := append([]ConstraintElem{}, col.Constraints...)
+		for i, constraint := range constraints {
+			if constraint.check != nil {
+				constraints[i].renames = renameColumn(constraint.renames, name, newName)
+			}
+		}
+		col.Constraints = constraints
+		t.Columns[colName] = col
+	}
+	return t
+}
+
+// clone returns a copy of the table definition that can be modified without
+// altering the original one
+func (t TableElem) clone() TableElem {
+	columns := map[string]ColumnElem{}
+	for name, col := range t.Columns {
+		columns[name] = col
+	}
+	t.Columns = columns
+	t.columnNames = append([]string{}, t.columnNames...)
+	t.PrimaryKeyConstraint.Columns = append([]string(nil), t.PrimaryKeyConstraint.Columns...)
+	t.UniqueKeyConstraints = append([]UniqueKeyConstraint{}, t.uniqueKeys()...)
+	for i, unique := range t.UniqueKeyConstraints {
+		t.UniqueKeyConstraints[i].cols = append([]string{}, unique.cols...)
+	}
+	t.CheckConstraints = append([]CheckConstraint{}, t.CheckConstraints...)
+
+	t.Indices = append([]IndexElem{}, t.Indices...)
+	for i, index := range t.Indices {
+		t.Indices[i].Columns = append([]string{}, index.Columns...)
+		t.Indices[i].Descending = append([]string(nil), index.Descending...)
+	}
+	fkeys := append([]ForeignKeyConstraint{}, t.ForeignKeyConstraints.FKeys...)
+	for i, fkey := range fkeys {
+		fkeys[i].Cols = append([]string{}, fkey.Cols...)
+	}
+	t.ForeignKeyConstraints.FKeys = fkeys
+	return t
+}
+
+func hasName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func removeName(names []string, name string) []string {
+	kept := []string{}
+	for _, n := range names {
+		if n != name {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+func renameName(names []string, name string, newName string) []string {
+	for i, n := range names {
+		if n == name {
+			names[i] = newName
+		}
+	}
+	return names
+}
@@ -0,0 +1,171 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlterTable(t *testing.T) {
+	dialect := NewDefaultDialect()
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("email", Varchar()).NotNull(),
+		Column("name", Varchar()),
+		Index("users", "email"),
+	)
+
+	var tests = []struct {
+		alter    AlterTableStmt
+		expected string
+	}{
+		{AlterTable(users).AddColumn(Column("age", Int()).Default(18)), "ALTER TABLE users ADD COLUMN age INT DEFAULT '18';"},
+		{AlterTable(users).DropColumn("name"), "ALTER TABLE users DROP COLUMN name;"},
+		{AlterTable(users).RenameColumn("name", "full_name"), "ALTER TABLE users RENAME COLUMN name TO full_name;"},
+		{AlterTable(users).AlterColumnType("name", Text()), "ALTER TABLE users ALTER COLUMN name TYPE TEXT;"},
+		{AlterTable(users).SetNotNull("name"), "ALTER TABLE users ALTER COLUMN name SET NOT NULL;"},
+		{AlterTable(users).DropNotNull("email"), "ALTER TABLE users ALTER COLUMN email DROP NOT NULL;"},
+		{AlterTable(users).SetDefault("name", "John"), "ALTER TABLE users ALTER COLUMN name SET DEFAULT 'John';"},
+		{AlterTable(users).DropDefault("name"), "ALTER TABLE users ALTER COLUMN name DROP DEFAULT;"},
+		{AlterTable(users).AddConstraint(UniqueKey("email", "name")), "ALTER TABLE users ADD CONSTRAINT u_users_email_name UNIQUE(email, name);"},
+		{AlterTable(users).AddConstraint(ForeignKey("id").References("accounts", "id")), "ALTER TABLE users ADD FOREIGN KEY(id) REFERENCES accounts(id);"},
+		{AlterTable(users).DropConstraint("u_users_email_name"), "ALTER TABLE users DROP CONSTRAINT u_users_email_name;"},
+		{AlterTable(users).AddIndex(Index("", "name")), "CREATE INDEX i_users_name ON users(name);"},
+		{AlterTable(users).DropIndex("i_users_email"), "DROP INDEX i_users_email;"},
+		{
+			AlterTable(users).RenameTo("members").AddColumn(Column("age", Int())),
+			"ALTER TABLE users RENAME TO members;\nALTER TABLE members ADD COLUMN age INT;",
+		},
+		{
+			AlterTable(users).AddColumn(Column("age", Int())).SetNotNull("age").DropColumn("name"),
+			"ALTER TABLE users ADD COLUMN age INT;\nALTER TABLE users ALTER COLUMN age SET NOT NULL, DROP COLUMN name;",
+		},
+	}
+	for _, tt := range tests {
+		statement := tt.alter.Build(dialect)
+		assert.Nil(t, statement.Err())
+		assert.Equal(t, tt.expected, statement.SQL())
+	}
+
+	statements, err := AlterTable(users).DropColumn("name").AddIndex(Index("", "email", "id")).Statements(dialect)
+	assert.Nil(t, err)
+	if assert.Len(t, statements, 2) {
+		assert.Equal(t, "ALTER TABLE users DROP COLUMN name;", statements[0].SQL())
+		assert.Equal(t, "CREATE INDEX i_users_email_id ON users(email, id);", statements[1].SQL())
+	}
+	_, err = AlterTable(users).DropColumn("age").Statements(dialect)
+	assert.NotNil(t, err)
+}
+
+func TestAlterTableConstraints(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("age", Int()),
+		PrimaryKey("id").Name("pk_users"),
+		UniqueKey("email").Name("uniq_email"),
+		Check(Column("age", Int()).Gte(0)).Name("positive_age"),
+	)
+
+	alter := AlterTable(users).
+		AddConstraint(Check(users.C("age").Lt(150)).Name("realistic_age")).
+		DropConstraint("positive_age")
+	assert.Equal(t,
+		"ALTER TABLE users ADD CONSTRAINT realistic_age CHECK (age < 150), DROP CONSTRAINT positive_age;",
+		alter.Build(NewDefaultDialect()).SQL())
+	altered := alter.Altered()
+	if assert.Len(t, altered.CheckConstraints, 1) {
+		assert.Equal(t, "realistic_age", altered.CheckConstraints[0].name)
+	}
+
+	altered = AlterTable(users).DropConstraint("pk_users").DropConstraint("uniq_email").Altered()
+	assert.Nil(t, altered.PrimaryKeyConstraint.Columns)
+	assert.False(t, altered.C("id").Options.PrimaryKey)
+	assert.Empty(t, altered.UniqueKeyConstraints)
+}
+
+func TestAlterTableChecks(t *testing.T) {
+	dialect := NewDefaultDialect()
+	age := Column("age", Int())
+	users := Table(
+		"users",
+		Column("id", Int()),
+		age.Check(age.Gt(0)),
+		Column("min_age", Int()).Check(Column("min_age", Int()).Lte(age)),
+		Check(age.Lt(150)).Name("realistic_age"),
+	)
+
+	renamed := AlterTable(users).RenameColumn("age", "years").RenameColumn("years", "age_years").Altered()
+	assert.Equal(t, "age_years INT CHECK (age_years > 0)", renamed.C("age_years").String(dialect))
+	assert.Equal(t, "min_age INT CHECK (min_age <= age_years)", renamed.C("min_age").String(dialect))
+	assert.Equal(t, "CONSTRAINT realistic_age CHECK (age_years < 150)", renamed.CheckConstraints[0].String(dialect))
+	assert.Equal(t, "age INT CHECK (age > 0)", users.C("age").String(dialect))
+
+	dropped := AlterTable(renamed).DropColumn("age_years").Altered()
+	assert.Equal(t, "min_age INT", dropped.C("min_age").String(dialect))
+	assert.Empty(t, dropped.CheckConstraints)
+}
+
+func TestAlterTableErrors(t *testing.T) {
+	dialect := NewDefaultDialect()
+	users := Table("users", Column("id", Int()).PrimaryKey(), Column("name", Varchar()))
+
+	var tests = []struct {
+		alter    AlterTableStmt
+		expected string
+	}{
+		{AlterTable(users).AddColumn(Column("name", Text())), "Column 'name' already exists in table users"},
+		{AlterTable(users).DropColumn("email"), "No such column 'email' in table users"},
+		{AlterTable(users).RenameColumn("name", "id"), "Column 'id' already exists in table users"},
+		{AlterTable(users).DropColumn("name").SetNotNull("name"), "No such column 'name' in table users"},
+		{AlterTable(users).AddConstraint(Column("age", Int())), "Cannot add qb.ColumnElem to table users"},
+		{AlterTable(MetaData().Table("accounts")).DropColumn("id"), "Table accounts not found"},
+	}
+	for _, tt := range tests {
+		err := tt.alter.Build(dialect).Err()
+		if assert.NotNil(t, err) {
+			assert.Equal(t, ErrCompile, err.(Error).Code)
+			assert.Equal(t, tt.expected, err.(Error).Orig.Error())
+		}
+	}
+}
+
+func TestAlterTableAltered(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("email", Varchar()).NotNull().Default("x"),
+		Column("name", Varchar()),
+		UniqueKey("email", "name"),
+		Index("users", "name"),
+	)
+
+	alter := AlterTable(users).
+		RenameColumn("name", "full_name").
+		DropNotNull("email").
+		DropDefault("email").
+		AlterColumnType("full_name", Text()).
+		AddColumn(Column("age", Int())).
+		RenameTo("members")
+	altered := alter.Altered()
+
+	assert.Equal(t, "members", altered.Name)
+	assert.Equal(t, []string{"id", "email", "full_name", "age"}, altered.ColumnNames())
+	assert.Empty(t, altered.C("email").Constraints)
+	assert.Equal(t, Text(), altered.C("full_name").Type)
+	assert.Equal(t, "members", altered.C("age").Table)
+	assert.Equal(t, []string{"full_name"}, altered.Indices[0].Columns)
+	assert.Equal(t, "members", altered.Indices[0].Table)
+	assert.Equal(t, []string{"email", "full_name"}, altered.UniqueKeyConstraints[0].cols)
+
+	// the original definition is left untouched
+	assert.Equal(t, []string{"id", "email", "name"}, users.ColumnNames())
+	assert.Len(t, users.C("email").Constraints, 2)
+	assert.Equal(t, []string{"name"}, users.Indices[0].Columns)
+
+	altered = AlterTable(users).DropColumn("name").Altered()
+	assert.Empty(t, altered.Indices)
+	assert.Empty(t, altered.UniqueKeyConstraints)
+}
@@ -0,0 +1,63 @@
+package qb
+
+// arithmetic operators
+
+// Add generates a <left> + <right> arithmetic clause
+func Add(left Clause, right interface{}) ArithmeticClause {
+	return Arithmetic(left, "+", GetClauseFrom(right))
+}
+
+// Sub generates a <left> - <right> arithmetic clause
+func Sub(left Clause, right interface{}) ArithmeticClause {
+	return Arithmetic(left, "-", GetClauseFrom(right))
+}
+
+// Mul generates a <left> * <right> arithmetic clause
+func Mul(left Clause, right interface{}) ArithmeticClause {
+	return Arithmetic(left, "*", GetClauseFrom(right))
+}
+
+// Div generates a <left> / <right> arithmetic clause
+func Div(left Clause, right interface{}) ArithmeticClause {
+	return Arithmetic(left, "/", GetClauseFrom(right))
+}
+
+// Mod generates a <left> % <right> arithmetic clause
+func Mod(left Clause, right interface{}) ArithmeticClause {
+	return Arithmetic(left, "%", GetClauseFrom(right))
+}
+
+// Arithmetic generates an arithmetic clause given the operator
+func Arithmetic(left Clause, op string, right Clause) ArithmeticClause {
+	return ArithmeticClause{BinaryExpression(left, op, right)}
+}
+
+// ArithmeticClause is a binary arithmetic expression
+// Nested arithmetic expressions are enclosed in parentheses
+type ArithmeticClause struct {
+	BinaryExpressionClause
+}
+
+// Accept calls the compiler VisitArithmetic method
+func (c ArithmeticClause) Accept(context Context) string {
+	return context.Compiler().VisitArithmetic(context, c)
+}
+
+// Neg generates a -<clause> unary minus clause
+func Neg(clause Clause) UnaryClause {
+	return UnaryClause{
+		Op:     "-",
+		Clause: clause,
+	}
+}
+
+// UnaryClause is a unary operator applied to a clause
+type UnaryClause struct {
+	Op     string
+	Clause Clause
+}
+
+// Accept calls the compiler VisitUnary method
+func (c UnaryClause) Accept(context Context) string {
+	return context.Compiler().VisitUnary(context, c)
+}
@@ -0,0 +1,36 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArithmetic(t *testing.T) {
+	score := Column("score", BigInt())
+	bonus := Column("bonus", BigInt())
+
+	for _, tt := range []struct {
+		clause Clause
+		expect string
+		binds  []interface{}
+	}{
+		{score.Add(1), "score + ?", []interface{}{1}},
+		{score.Sub(bonus), "score - bonus", emptyBinds},
+		{score.Mul(2), "score * ?", []interface{}{2}},
+		{score.Div(bonus), "score / bonus", emptyBinds},
+		{score.Mod(3), "score % ?", []interface{}{3}},
+		{score.Neg(), "-score", emptyBinds},
+		{Neg(Bind(5)), "-?", []interface{}{5}},
+		{Neg(score.Neg()), "-(-score)", emptyBinds},
+		{Mul(score.Add(bonus), 2), "(score + bonus) * ?", []interface{}{2}},
+		{Sub(score, Sub(bonus, 1)), "score - (bonus - ?)", []interface{}{1}},
+		{Neg(score.Add(bonus)), "-(score + bonus)", emptyBinds},
+		{Gt(score.Add(bonus), 10), "score + bonus > ?", []interface{}{10}},
+		{Add(Sum(score), SQLText("1")), "SUM(score) + 1", emptyBinds},
+	} {
+		ctx := NewCompilerContext(NewDefaultDialect())
+		assert.Equal(t, tt.expect, tt.clause.Accept(ctx))
+		assert.Equal(t, tt.binds, ctx.Binds())
+	}
+}
@@ -1,5 +1,7 @@
 package qb
 
+import "reflect"
+
 // SQLText returns a raw SQL clause
 func SQLText(text string) TextClause {
 	return TextClause{Text: text}
@@ -15,6 +17,18 @@ func (c TextClause) Accept(context Context) string {
 	return context.Compiler().VisitText(context, c)
 }
 
+// ErrorClause is a clause that could not be built
+// Compiling it records its error in the context, and produces no SQL
+type ErrorClause struct {
+	Err error
+}
+
+// Accept records the error in the context
+func (c ErrorClause) Accept(context Context) string {
+	context.AddError(c.Err)
+	return ""
+}
+
 // List returns a list-of-clauses clause
 func List(clauses ...Clause) ListClause {
 	return ListClause{
@@ -32,6 +46,25 @@ func (c ListClause) Accept(context Context) string {
 	return context.Compiler().VisitList(context, c)
 }
 
+// Label returns a labelled expression clause (<clause> AS <name>)
+func Label(name string, clause Clause) LabelledClause {
+	return LabelledClause{
+		Name:   name,
+		Clause: clause,
+	}
+}
+
+// LabelledClause is an expression given a name in a select list
+type LabelledClause struct {
+	Name   string
+	Clause Clause
+}
+
+// Accept calls the compiler VisitLabelled method
+func (c LabelledClause) Accept(context Context) string {
+	return context.Compiler().VisitLabelled(context, c)
+}
+
 // Bind a value
 func Bind(value interface{}) BindClause {
 	return BindClause{
@@ -61,8 +94,9 @@ func GetClauseFrom(value interface{}) Clause {
 
 // GetListFrom returns a list clause from any list
 //
-// If only one value is passed and is a ListClause, it is returned
-// as-is.
+// If only one value is passed and is a ListClause or a subquery (SelectStmt,
+// CompoundClause), it is returned as-is. If it is a slice (but a []byte), the
+// list is built from its elements.
 // In any other case, a ListClause is built with each value wrapped
 // by a Bind() if not already a Clause
 func GetListFrom(values ...interface{}) Clause {
@@ -70,6 +104,12 @@ func GetListFrom(values ...interface{}) Clause {
 		if clause, ok := values[0].(ListClause); ok {
 			return clause
 		}
+		if isSubQuery(values[0]) {
+			return values[0].(Clause)
+		}
+		if isSlice(values[0]) {
+			values = sliceValues(values[0])
+		}
 	}
 
 	var clauses []Clause
@@ -105,3 +145,61 @@ type ExistsClause struct {
 func (c ExistsClause) Accept(context Context) string {
 	return context.Compiler().VisitExists(context, c)
 }
+
+// Any returns a ANY (<subquery>) clause, to be used as the right side
+// of a comparison
+// Eq(usersTable.C("id"), Any(sel))
+func Any(query Query) QuantifiedClause {
+	return QuantifiedClause{
+		Quantifier: "ANY",
+		Query:      query,
+	}
+}
+
+// All returns a ALL (<subquery>) clause, to be used as the right side
+// of a comparison
+// Gt(usersTable.C("score"), All(sel))
+func All(query Query) QuantifiedClause {
+	return QuantifiedClause{
+		Quantifier: "ALL",
+		Query:      query,
+	}
+}
+
+// Some returns a SOME (<subquery>) clause, to be used as the right side
+// of a comparison
+func Some(query Query) QuantifiedClause {
+	return QuantifiedClause{
+		Quantifier: "SOME",
+		Query:      query,
+	}
+}
+
+// QuantifiedClause is a ANY, ALL or SOME clause
+type QuantifiedClause struct {
+	Quantifier string
+	Query      Query
+}
+
+// Accept calls compiler VisitQuantified method
+func (c QuantifiedClause) Accept(context Context) string {
+	return context.Compiler().VisitQuantified(context, c)
+}
+
+// isSlice returns true if value is a slice, a []byte value excepted
+func isSlice(value interface{}) bool {
+	if _, ok := value.([]byte); ok || value == nil {
+		return false
+	}
+	return reflect.TypeOf(value).Kind() == reflect.Slice
+}
+
+// sliceValues returns the elements of a slice
+func sliceValues(slice interface{}) []interface{} {
+	v := reflect.ValueOf(slice)
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
@@ -28,6 +28,10 @@ type ColumnElem struct {
 	Table       string // This field should be lazily set by Table() function
 	Constraints []ConstraintElem
 	Options     ColumnOptions
+
+	// err is set on columns that could not be found, and is reported when
+	// the column is compiled
+	err error
 }
 
 // AutoIncrement set up “auto increment” semantics for an integer column.
@@ -60,7 +64,7 @@ func (c ColumnElem) String(dialect Dialect) string {
 		colSpec = dialect.CompileType(c.Type)
 		constraintNames := []string{}
 		for _, constraint := range c.Constraints {
-			constraintNames = append(constraintNames, constraint.String())
+			constraintNames = append(constraintNames, constraint.compile(dialect))
 		}
 		if len(constraintNames) != 0 {
 			colSpec = fmt.Sprintf("%s %s", colSpec, strings.Join(constraintNames, " "))
@@ -105,12 +109,56 @@ func (c ColumnElem) Unique() ColumnElem {
 	return c
 }
 
+// Check adds a CHECK constraint with the given boolean expression to column
+// type
+func (c ColumnElem) Check(clause Clause) ColumnElem {
+	c.Constraints = append(c.Constraints, ConstraintElem{Name: "CHECK", check: clause})
+	return c
+}
+
 // Constraint adds a custom constraint to column type
 func (c ColumnElem) Constraint(name string) ColumnElem {
 	c.Constraints = append(c.Constraints, Constraint(name))
 	return c
 }
 
+// Label wraps the Label(name string, clause Clause)
+func (c ColumnElem) Label(name string) LabelledClause {
+	return Label(name, c)
+}
+
+// arithmetic wrappers
+
+// Add wraps the Add(col ColumnElem, value interface{})
+func (c ColumnElem) Add(value interface{}) ArithmeticClause {
+	return Add(c, value)
+}
+
+// Sub wraps the Sub(col ColumnElem, value interface{})
+func (c ColumnElem) Sub(value interface{}) ArithmeticClause {
+	return Sub(c, value)
+}
+
+// Mul wraps the Mul(col ColumnElem, value interface{})
+func (c ColumnElem) Mul(value interface{}) ArithmeticClause {
+	return Mul(c, value)
+}
+
+// Div wraps the Div(col ColumnElem, value interface{})
+func (c ColumnElem) Div(value interface{}) ArithmeticClause {
+	return Div(c, value)
+}
+
+// Mod wraps the Mod(col ColumnElem, value interface{})
+func (c ColumnElem) Mod(value interface{}) ArithmeticClause {
+	return Mod(c, value)
+}
+
+// Neg wraps the Neg(col ColumnElem)
+func (c ColumnElem) Neg() UnaryClause {
+	return Neg(c)
+}
+
 // conditional wrappers
 
 // Like wraps the Like(col ColumnElem, pattern string)
@@ -118,13 +166,23 @@ func (c ColumnElem) Like(pattern string) Clause {
 	return Like(c, pattern)
 }
 
+// NotLike wraps the NotLike(col ColumnElem, pattern string)
+func (c ColumnElem) NotLike(pattern string) Clause {
+	return NotLike(c, pattern)
+}
+
+// ILike wraps the ILike(col ColumnElem, pattern string)
+func (c ColumnElem) ILike(pattern string) Clause {
+	return ILike(c, pattern)
+}
+
 // NotIn wraps the NotIn(col ColumnElem, values ...interface{})
-func (c ColumnElem) NotIn(values ...interface{}) Clause {
+func (c ColumnElem) NotIn(values ...interface{}) InClause {
 	return NotIn(c, values...)
 }
 
 // In wraps the In(col ColumnElem, values ...interface{})
-func (c ColumnElem) In(values ...interface{}) Clause {
+func (c ColumnElem) In(values ...interface{}) InClause {
 	return In(c, values...)
 }
 
@@ -157,3 +215,23 @@ func (c ColumnElem) Gte(value interface{}) Clause {
 func (c ColumnElem) Lte(value interface{}) Clause {
 	return Lte(c, value)
 }
+
+// IsNull wraps the IsNull(col ColumnElem)
+func (c ColumnElem) IsNull() Clause {
+	return IsNull(c)
+}
+
+// IsNotNull wraps the IsNotNull(col ColumnElem)
+func (c ColumnElem) IsNotNull() Clause {
+	return IsNotNull(c)
+}
+
+// Between wraps the Between(col ColumnElem, low interface{}, high interface{})
+func (c ColumnElem) Between(low interface{}, high interface{}) Clause {
+	return Between(c, low, high)
+}
+
+// NotBetween wraps the NotBetween(col ColumnElem, low interface{}, high interface{})
+func (c ColumnElem) NotBetween(low interface{}, high interface{}) Clause {
+	return NotBetween(c, low, high)
+}
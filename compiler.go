@@ -2,26 +2,42 @@ package qb
 
 // Compiler is a visitor that produce SQL from various types of Clause
 type Compiler interface {
+	CompileAlterTable(Context, AlterTableStmt) []string
 	VisitAggregate(Context, AggregateClause) string
 	VisitAlias(Context, AliasClause) string
+	VisitAlterTable(Context, AlterTableStmt) string
+	VisitArithmetic(Context, ArithmeticClause) string
+	VisitBetween(Context, BetweenClause) string
 	VisitBinary(Context, BinaryExpressionClause) string
 	VisitBind(Context, BindClause) string
 	VisitColumn(Context, ColumnElem) string
 	VisitCombiner(Context, CombinerClause) string
+	VisitCompound(Context, CompoundClause) string
 	VisitDelete(Context, DeleteStmt) string
+	VisitDropIndex(Context, DropIndexStmt) string
+	VisitExcluded(Context, ExcludedClause) string
 	VisitExists(Context, ExistsClause) string
 	VisitForUpdate(Context, ForUpdateClause) string
 	VisitHaving(Context, HavingClause) string
 	VisitIn(Context, InClause) string
+	VisitIndex(Context, IndexElem) string
 	VisitInsert(Context, InsertStmt) string
 	VisitJoin(Context, JoinClause) string
 	VisitLabel(Context, string) string
+	VisitLabelled(Context, LabelledClause) string
 	VisitList(Context, ListClause) string
+	VisitNot(Context, NotClause) string
 	VisitOrderBy(Context, OrderByClause) string
+	VisitOver(Context, OverClause) string
+	VisitQuantified(Context, QuantifiedClause) string
+	VisitSavepoint(Context, SavepointStmt) string
 	VisitSelect(Context, SelectStmt) string
 	VisitTable(Context, TableElem) string
 	VisitText(Context, TextClause) string
+	VisitUnary(Context, UnaryClause) string
 	VisitUpdate(Context, UpdateStmt) string
 	VisitUpsert(Context, UpsertStmt) string
 	VisitWhere(Context, WhereClause) string
+	VisitWindow(Context, WindowClause) string
+	VisitWith(Context, WithClause) string
 }
@@ -70,6 +70,24 @@ var compileTests = []struct {
 		"SELECT 1\nFROM group\nFOR UPDATE OF user, group",
 		emptyBinds,
 	},
+	{
+		TTUser.C("main_group_id").Eq(Any(Select(TTGroup.C("id")).From(TTGroup))),
+		"user.main_group_id = ANY (SELECT group.id\nFROM group)",
+		emptyBinds,
+	},
+	{
+		TTUser.C("id").Gt(All(Select(TTGroup.C("id")).From(TTGroup))),
+		"user.id > ALL (SELECT group.id\nFROM group)",
+		emptyBinds,
+	},
+	{
+		TTUser.C("id").NotEq(Some(Select(TTGroup.C("id")).From(TTGroup))),
+		"user.id != SOME (SELECT group.id\nFROM group)",
+		emptyBinds,
+	},
+	{Savepoint("sp1"), "SAVEPOINT sp1", emptyBinds},
+	{RollbackToSavepoint("sp1"), "ROLLBACK TO SAVEPOINT sp1", emptyBinds},
+	{ReleaseSavepoint("sp1"), "RELEASE SAVEPOINT sp1", emptyBinds},
 }
 
 func TestCompile(t *testing.T) {
@@ -17,6 +17,7 @@ type CompilerContext struct {
 	binds            []interface{}
 	defaultTableName string
 	inSubQuery       bool
+	errors           []error
 
 	dialect  Dialect
 	compiler Compiler
@@ -68,3 +69,13 @@ func (ctx *CompilerContext) InSubQuery() bool {
 func (ctx *CompilerContext) SetInSubQuery(inSubQuery bool) {
 	ctx.inSubQuery = inSubQuery
 }
+
+// AddError records an error that occurred during the compilation
+func (ctx *CompilerContext) AddError(err error) {
+	ctx.errors = append(ctx.errors, err)
+}
+
+// Errors returns the errors that occurred during the compilation
+func (ctx *CompilerContext) Errors() []error {
+	return ctx.errors
+}
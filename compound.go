@@ -0,0 +1,142 @@
+package qb
+
+// Union generates a UNION compound select of the given select statements
+func Union(selects ...SelectStmt) CompoundClause {
+	return Compound("UNION", selects...)
+}
+
+// UnionAll generates a UNION ALL compound select of the given select
+// statements
+func UnionAll(selects ...SelectStmt) CompoundClause {
+	return Compound("UNION ALL", selects...)
+}
+
+// Intersect generates an INTERSECT compound select of the given select
+// statements
+func Intersect(selects ...SelectStmt) CompoundClause {
+	return Compound("INTERSECT", selects...)
+}
+
+// Except generates an EXCEPT compound select of the given select statements
+func Except(selects ...SelectStmt) CompoundClause {
+	return Compound("EXCEPT", selects...)
+}
+
+// Compound generates a new compound select given the operator and the
+// select statements it combines
+func Compound(operator string, selects ...SelectStmt) CompoundClause {
+	return CompoundClause{
+		Operator: operator,
+		Selects:  selects,
+	}
+}
+
+// CompoundClause is the base struct for building compound selects
+// (UNION, UNION ALL, INTERSECT, EXCEPT)
+// It satisfies the Selectable interface, and can be used as a subquery once
+// aliased
+type CompoundClause struct {
+	Operator      string
+	Selects       []SelectStmt
+	OrderByClause *OrderByClause
+	OffsetValue   *int
+	LimitValue    *int
+}
+
+// OrderBy sets the order by clause of the compound select
+// The columns should be obtained with CompoundClause.C() so they are not
+// qualified by a table name
+func (c CompoundClause) OrderBy(columns ...ColumnElem) CompoundClause {
+	c.OrderByClause = &OrderByClause{columns, "ASC"}
+	return c
+}
+
+// Asc sets the t type of current order by clause
+// NOTE: Please use it after calling OrderBy()
+func (c CompoundClause) Asc() CompoundClause {
+	c.OrderByClause.t = "ASC"
+	return c
+}
+
+// Desc sets the t type of current order by clause
+// NOTE: Please use it after calling OrderBy()
+func (c CompoundClause) Desc() CompoundClause {
+	c.OrderByClause.t = "DESC"
+	return c
+}
+
+// Limit sets the limit number of rows
+func (c CompoundClause) Limit(limit int) CompoundClause {
+	c.LimitValue = &limit
+	return c
+}
+
+// Offset sets the offset
+func (c CompoundClause) Offset(value int) CompoundClause {
+	c.OffsetValue = &value
+	return c
+}
+
+// LimitOffset sets the limit & offset values of the compound select
+func (c CompoundClause) LimitOffset(limit, offset int) CompoundClause {
+	c.LimitValue = &limit
+	c.OffsetValue = &offset
+	return c
+}
+
+// Accept calls the compiler VisitCompound method
+func (c CompoundClause) Accept(context Context) string {
+	return context.Compiler().VisitCompound(context, c)
+}
+
+// Build compiles the compound select and returns the Stmt
+func (c CompoundClause) Build(dialect Dialect) *Stmt {
+	context := NewCompilerContext(dialect)
+	statement := Statement()
+	statement.AddSQLClause(c.Accept(context))
+	statement.AddBinding(context.Binds()...)
+	statement.AddError(context.Errors()...)
+
+	return statement
+}
+
+// All returns the columns of the compound select
+func (c CompoundClause) All() []Clause {
+	var clauses []Clause
+	for _, col := range c.ColumnList() {
+		clauses = append(clauses, col)
+	}
+	return clauses
+}
+
+// ColumnList returns the columns of the compound select, which are named
+// after the columns of the first select statement
+func (c CompoundClause) ColumnList() []ColumnElem {
+	if len(c.Selects) == 0 {
+		return nil
+	}
+	var cols []ColumnElem
+	for _, col := range c.Selects[0].ColumnList() {
+		col.Table = ""
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// C returns the compound select column with the given name
+// If the first select list has no such column, a column with the given name
+// is returned anyway so that columns of raw select lists can be referred to.
+func (c CompoundClause) C(name string) ColumnElem {
+	for _, col := range c.ColumnList() {
+		if col.Name == name {
+			return col
+		}
+	}
+	return ColumnElem{Name: name}
+}
+
+// DefaultName returns an empty string because compound selects have no name
+// by default
+func (c CompoundClause) DefaultName() string {
+	return ""
+}
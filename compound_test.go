@@ -0,0 +1,120 @@
+package qb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompound(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	admins := Table(
+		"admins",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+
+	s1 := Select(users.C("id"), users.C("email")).From(users).Where(users.C("id").Gt(5))
+	s2 := Select(admins.C("id"), admins.C("email")).From(admins)
+
+	assert.Equal(t, Compound("UNION", s1, s2), Union(s1, s2))
+	assert.Equal(t, Compound("UNION ALL", s1, s2), UnionAll(s1, s2))
+	assert.Equal(t, Compound("INTERSECT", s1, s2), Intersect(s1, s2))
+	assert.Equal(t, Compound("EXCEPT", s1, s2), Except(s1, s2))
+
+	union := Union(s1, s2)
+	assert.Equal(t, "", union.DefaultName())
+	assert.Equal(t, 2, len(union.All()))
+	assert.Equal(t, ColumnElem{Name: "email", Type: Varchar()}, union.C("email"))
+	assert.Equal(t, ColumnElem{Name: "unknown"}, union.C("unknown"))
+	assert.Nil(t, Union().ColumnList())
+
+	ctx := NewCompilerContext(NewDefaultDialect())
+	sql := union.OrderBy(union.C("email")).Desc().LimitOffset(10, 20).Accept(ctx)
+	assert.Equal(t, strings.Join([]string{
+		"SELECT id, email",
+		"FROM users",
+		"WHERE id > ?",
+		"UNION",
+		"SELECT id, email",
+		"FROM admins",
+		"ORDER BY email DESC",
+		"LIMIT 10 OFFSET 20",
+	}, "\n"), sql)
+	assert.Equal(t, []interface{}{5}, ctx.Binds())
+
+	statement := UnionAll(s1, s2).OrderBy(union.C("id")).Asc().Limit(5).Offset(2).Build(NewDefaultDialect())
+	assert.Equal(t, strings.Join([]string{
+		"SELECT id, email",
+		"FROM users",
+		"WHERE id > ?",
+		"UNION ALL",
+		"SELECT id, email",
+		"FROM admins",
+		"ORDER BY id ASC",
+		"LIMIT 5 OFFSET 2;",
+	}, "\n"), statement.SQL())
+	assert.Equal(t, []interface{}{5}, statement.Bindings())
+}
+
+func TestCompoundMemberLimit(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+
+	first := Select(users.C("email")).From(users).OrderBy(users.C("id")).Limit(1)
+	last := Select(users.C("email")).From(users).OrderBy(users.C("id")).Desc().Limit(1)
+
+	ctx := NewCompilerContext(NewDefaultDialect())
+	assert.Equal(t, strings.Join([]string{
+		"(SELECT email",
+		"FROM users",
+		"ORDER BY id ASC",
+		"LIMIT 1)",
+		"UNION",
+		"(SELECT email",
+		"FROM users",
+		"ORDER BY id DESC",
+		"LIMIT 1)",
+	}, "\n"), Union(first, last).Accept(ctx))
+}
+
+func TestCompoundAlias(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	admins := Table(
+		"admins",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+
+	everyone := Alias("everyone", Union(
+		Select(users.C("id"), users.C("email")).From(users),
+		Select(admins.C("id"), admins.C("email")).From(admins),
+	))
+
+	sel := Select(everyone.C("email")).
+		From(everyone).
+		Where(everyone.C("id").Eq(1))
+
+	ctx := NewCompilerContext(NewDefaultDialect())
+	assert.Equal(t, strings.Join([]string{
+		"SELECT email",
+		"FROM (SELECT id, email",
+		"FROM users",
+		"UNION",
+		"SELECT id, email",
+		"FROM admins) AS everyone",
+		"WHERE id = ?",
+	}, "\n"), sel.Accept(ctx))
+}
@@ -1,5 +1,7 @@
 package qb
 
+import "reflect"
+
 // conditional generators, comparator functions
 
 // Like generates a like conditional sql clause
@@ -7,34 +9,77 @@ func Like(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, "LIKE", GetClauseFrom(right))
 }
 
+// NotLike generates a not like conditional sql clause
+func NotLike(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, "NOT LIKE", GetClauseFrom(right))
+}
+
+// ILike generates a case insensitive like conditional sql clause
+// It is emulated with LOWER(left) LIKE LOWER(right) on the dialects that do
+// not support ILIKE
+func ILike(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, "ILIKE", GetClauseFrom(right))
+}
+
 // In generates an IN conditional sql clause
+// The values can be given as a single slice, whose elements are then bound
+// separately. An empty list of values generates an always false condition
 func In(left Clause, values ...interface{}) InClause {
-	return InClause{BinaryExpressionClause{
-		Left:  left,
-		Op:    "IN",
-		Right: GetListFrom(values...),
-	}}
+	return newInClause(left, "IN", values)
 }
 
 // NotIn generates an NOT IN conditional sql clause
+// The values can be given as a single slice, whose elements are then bound
+// separately. An empty list of values generates an always true condition
 func NotIn(left Clause, values ...interface{}) InClause {
-	return InClause{BinaryExpressionClause{
-		Left:  left,
-		Op:    "NOT IN",
-		Right: GetListFrom(values...),
-	}}
+	return newInClause(left, "NOT IN", values)
+}
+
+func newInClause(left Clause, op string, values []interface{}) InClause {
+	in := InClause{BinaryExpressionClause: BinaryExpression(left, op, GetListFrom(values...))}
+	if len(values) == 1 && isSlice(values[0]) {
+		in.slice = values[0]
+	}
+	return in
 }
 
 // NotEq generates a not equal conditional sql clause
+// A nil right value generates a IS NOT NULL clause
 func NotEq(left Clause, right interface{}) BinaryExpressionClause {
+	if isNil(right) {
+		return IsNotNull(left)
+	}
 	return BinaryExpression(left, "!=", GetClauseFrom(right))
 }
 
 // Eq generates a equals conditional sql clause
+// A nil right value generates a IS NULL clause
 func Eq(left Clause, right interface{}) BinaryExpressionClause {
+	if isNil(right) {
+		return IsNull(left)
+	}
 	return BinaryExpression(left, "=", GetClauseFrom(right))
 }
 
+// IsNull generates a IS NULL conditional sql clause
+func IsNull(left Clause) BinaryExpressionClause {
+	return BinaryExpression(left, "IS", SQLText("NULL"))
+}
+
+// IsNotNull generates a IS NOT NULL conditional sql clause
+func IsNotNull(left Clause) BinaryExpressionClause {
+	return BinaryExpression(left, "IS NOT", SQLText("NULL"))
+}
+
+// isNil returns true if value is nil or a nil pointer
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
 // Gt generates a greater than conditional sql clause
 func Gt(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, ">", GetClauseFrom(right))
@@ -55,6 +100,50 @@ func Lte(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, "<=", GetClauseFrom(right))
 }
 
+// Between generates a BETWEEN conditional sql clause
+func Between(left Clause, low interface{}, high interface{}) BetweenClause {
+	return BetweenClause{
+		Left: left,
+		Low:  GetClauseFrom(low),
+		High: GetClauseFrom(high),
+	}
+}
+
+// NotBetween generates a NOT BETWEEN conditional sql clause
+func NotBetween(left Clause, low interface{}, high interface{}) BetweenClause {
+	between := Between(left, low, high)
+	between.Not = true
+	return between
+}
+
+// BetweenClause is a <left> (NOT) BETWEEN <low> AND <high> clause
+type BetweenClause struct {
+	Left Clause
+	Low  Clause
+	High Clause
+	Not  bool
+}
+
+// Accept calls the compiler VisitBetween method
+func (c BetweenClause) Accept(context Context) string {
+	return context.Compiler().VisitBetween(context, c)
+}
+
+// Not generates a NOT conditional sql clause negating the given clause
+func Not(clause Clause) NotClause {
+	return NotClause{Clause: clause}
+}
+
+// NotClause is a NOT (<clause>) clause
+type NotClause struct {
+	Clause Clause
+}
+
+// Accept calls the compiler VisitNot method
+func (c NotClause) Accept(context Context) string {
+	return context.Compiler().VisitNot(context, c)
+}
+
 // BinaryExpression generates a condition object to use in update, delete & select statements
 func BinaryExpression(left Clause, op string, right Clause) BinaryExpressionClause {
 	return BinaryExpressionClause{
@@ -79,6 +168,21 @@ func (c BinaryExpressionClause) Accept(context Context) string {
 // InClause is a IN or NOT IN binary expression
 type InClause struct {
 	BinaryExpressionClause
+	// Array is the slice of values to bind as a single array parameter, on
+	// the dialects supporting it. It is set by AsArray()
+	Array interface{}
+
+	slice interface{}
+}
+
+// AsArray makes the values given as a single slice bound as a single array
+// parameter, on the dialects supporting it: postgres compiles the clause as
+// <left> = ANY($1) or <left> != ALL($1). This keeps the statement the same
+// whatever the number of values.
+// The other dialects bind the elements separately
+func (c InClause) AsArray() InClause {
+	c.Array = c.slice
+	return c
 }
 
 // Accept calls the compiler VisitBinary method
@@ -49,6 +49,39 @@ func (suite *ConditionalTestSuite) TestConditionalIn() {
 	assert.Equal(suite.T(), []interface{}{"USA", "England", "Sweden"}, bindings)
 }
 
+func (suite *ConditionalTestSuite) TestConditionalInSlice() {
+	sql := In(suite.country, []string{"USA", "England"}).Accept(suite.ctx)
+	assert.Equal(suite.T(), "country IN (?, ?)", sql)
+	assert.Equal(suite.T(), []interface{}{"USA", "England"}, suite.ctx.Binds())
+
+	ctx := NewCompilerContext(suite.dialect)
+	sql = suite.score.NotIn([]int64{1, 2, 3}).Accept(ctx)
+	assert.Equal(suite.T(), "score NOT IN (?, ?, ?)", sql)
+	assert.Equal(suite.T(), []interface{}{int64(1), int64(2), int64(3)}, ctx.Binds())
+
+	ctx = NewCompilerContext(suite.dialect)
+	sql = In(suite.country, []byte("USA")).AsArray().Accept(ctx)
+	assert.Equal(suite.T(), "country IN (?)", sql)
+	assert.Equal(suite.T(), []interface{}{[]byte("USA")}, ctx.Binds())
+}
+
+func (suite *ConditionalTestSuite) TestConditionalInEmpty() {
+	var tests = []struct {
+		clause   Clause
+		expected string
+	}{
+		{In(suite.country), "1 = 0"},
+		{NotIn(suite.country), "1 = 1"},
+		{In(suite.country, []string{}), "1 = 0"},
+		{suite.country.NotIn([]string(nil)), "1 = 1"},
+		{And(suite.score.Gt(1), In(suite.country, []string{})), "(score > ? AND 1 = 0)"},
+	}
+	for _, tt := range tests {
+		assert.Equal(suite.T(), tt.expected, tt.clause.Accept(suite.ctx))
+	}
+	assert.Equal(suite.T(), []interface{}{1}, suite.ctx.Binds())
+}
+
 func (suite *ConditionalTestSuite) TestConditionalNotEq() {
 	notEq := NotEq(suite.country, "USA")
 
@@ -109,6 +142,66 @@ func (suite *ConditionalTestSuite) TestConditionalLte() {
 	assert.Equal(suite.T(), []interface{}{1500}, bindings)
 }
 
+func (suite *ConditionalTestSuite) TestConditionalNotLike() {
+	notLike := NotLike(suite.country, "%land%")
+	sql := notLike.Accept(suite.ctx)
+	bindings := suite.ctx.Binds()
+
+	assert.Equal(suite.T(), "country NOT LIKE ?", sql)
+	assert.Equal(suite.T(), []interface{}{"%land%"}, bindings)
+}
+
+func (suite *ConditionalTestSuite) TestConditionalILike() {
+	sql := suite.country.ILike("%land%").Accept(suite.ctx)
+	assert.Equal(suite.T(), "country ILIKE ?", sql)
+
+	ctx := NewCompilerContext(restrictedDialect{suite.dialect, 0})
+	sql = suite.country.ILike("%land%").Accept(ctx)
+	assert.Equal(suite.T(), "LOWER(country) LIKE LOWER(?)", sql)
+	assert.Equal(suite.T(), []interface{}{"%land%"}, ctx.Binds())
+}
+
+func (suite *ConditionalTestSuite) TestConditionalIsNull() {
+	var nilPointer *string
+
+	var tests = []struct {
+		clause   Clause
+		expected string
+	}{
+		{IsNull(suite.country), "country IS NULL"},
+		{IsNotNull(suite.country), "country IS NOT NULL"},
+		{suite.country.IsNull(), "country IS NULL"},
+		{suite.country.IsNotNull(), "country IS NOT NULL"},
+		{Eq(suite.country, nil), "country IS NULL"},
+		{NotEq(suite.country, nil), "country IS NOT NULL"},
+		{suite.country.Eq(nilPointer), "country IS NULL"},
+	}
+	for _, tt := range tests {
+		assert.Equal(suite.T(), tt.expected, tt.clause.Accept(suite.ctx))
+	}
+	assert.Empty(suite.T(), suite.ctx.Binds())
+}
+
+func (suite *ConditionalTestSuite) TestConditionalBetween() {
+	sql := Between(suite.score, 1000, 2000).Accept(suite.ctx)
+	assert.Equal(suite.T(), "score BETWEEN ? AND ?", sql)
+	assert.Equal(suite.T(), []interface{}{1000, 2000}, suite.ctx.Binds())
+
+	ctx := NewCompilerContext(suite.dialect)
+	sql = suite.score.NotBetween(1000, Column("max_score", BigInt())).Accept(ctx)
+	assert.Equal(suite.T(), "score NOT BETWEEN ? AND max_score", sql)
+	assert.Equal(suite.T(), []interface{}{1000}, ctx.Binds())
+}
+
+func (suite *ConditionalTestSuite) TestConditionalNot() {
+	sql := Not(Eq(suite.country, "Turkey")).Accept(suite.ctx)
+	assert.Equal(suite.T(), "NOT (country = ?)", sql)
+
+	ctx := NewCompilerContext(suite.dialect)
+	sql = Not(Or(suite.country.IsNull(), suite.score.Lt(10))).Accept(ctx)
+	assert.Equal(suite.T(), "NOT (country IS NULL OR score < ?)", sql)
+}
+
 func TestConditionalTestSuite(t *testing.T) {
 	suite.Run(t, new(ConditionalTestSuite))
 }
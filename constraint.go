@@ -3,37 +3,43 @@ package qb
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Null generates generic null constraint
 func Null() ConstraintElem {
-	return ConstraintElem{"NULL"}
+	return ConstraintElem{Name: "NULL"}
 }
 
 // NotNull generates generic not null constraint
 func NotNull() ConstraintElem {
-	return ConstraintElem{"NOT NULL"}
+	return ConstraintElem{Name: "NOT NULL"}
 }
 
 // Default generates generic default constraint
 func Default(value interface{}) ConstraintElem {
-	return ConstraintElem{fmt.Sprintf("DEFAULT '%v'", value)}
+	return ConstraintElem{Name: fmt.Sprintf("DEFAULT '%v'", value)}
 }
 
 // Unique generates generic unique constraint
 // if cols are given, then composite unique constraint will be built
 func Unique() ConstraintElem {
-	return ConstraintElem{"UNIQUE"}
+	return ConstraintElem{Name: "UNIQUE"}
 }
 
 // Constraint generates a custom constraint due to variation of dialects
 func Constraint(name string) ConstraintElem {
-	return ConstraintElem{name}
+	return ConstraintElem{Name: name}
 }
 
 // ConstraintElem is the definition of column & table constraints
 type ConstraintElem struct {
 	Name string
+
+	// check is the expression of a CHECK constraint
+	check Clause
+	// renames maps the columns referenced by check to their new names
+	renames map[string]string
 }
 
 // String returns the constraint as an sql clause
@@ -41,14 +47,34 @@ func (c ConstraintElem) String() string {
 	return c.Name
 }
 
+// compile returns the constraint as an sql clause, the expression of a check
+// constraint being compiled for the dialect
+func (c ConstraintElem) compile(dialect Dialect) string {
+	if c.check != nil {
+		return fmt.Sprintf("CHECK (%s)", literalCompiler{Compiler: dialect.GetCompiler(), renames: c.renames}.compile(dialect, "", c.check))
+	}
+	return c.Name
+}
+
+// constraintName returns the CONSTRAINT <name> prefix of a named table
+// constraint
+func constraintName(dialect Dialect, name string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf("CONSTRAINT %s ", dialect.Escape(name))
+}
+
 // PrimaryKey generates a primary key constraint of any table
 func PrimaryKey(cols ...string) PrimaryKeyConstraint {
-	return PrimaryKeyConstraint{cols}
+	return PrimaryKeyConstraint{Columns: cols}
 }
 
 // PrimaryKeyConstraint is the definition of primary key constraints of any table
 type PrimaryKeyConstraint struct {
 	Columns []string
+
+	name string
 }
 
 // String returns the primary key constraints as an sql clause
@@ -58,7 +84,14 @@ func (c PrimaryKeyConstraint) String(dialect Dialect) string {
 		cols = append(cols, dialect.Escape(col))
 	}
 
-	return fmt.Sprintf("PRIMARY KEY(%s)", strings.Join(cols, ", "))
+	return fmt.Sprintf("%sPRIMARY KEY(%s)", constraintName(dialect, c.name), strings.Join(cols, ", "))
+}
+
+// Name set the constraint name
+// A named primary key is never inlined in the column definition
+func (c PrimaryKeyConstraint) Name(name string) PrimaryKeyConstraint {
+	c.name = name
+	return c
 }
 
 // ForeignKey generates a foreign key for table constraint definitions
@@ -87,11 +120,17 @@ type ForeignKeyConstraint struct {
 	RefCols        []string
 	ActionOnUpdate string
 	ActionOnDelete string
+
+	// name is the name of the constraint, if any
+	name string
+	// err is set by an invalid cascading action, and is reported by the table
+	err error
 }
 
 func (fkey ForeignKeyConstraint) String(dialect Dialect) string {
-	ddl := fmt.Sprintf(
-		"\tFOREIGN KEY(%s) REFERENCES %s(%s)",
+	ddl := "\t" + constraintName(dialect, fkey.name)
+	ddl += fmt.Sprintf(
+		"FOREIGN KEY(%s) REFERENCES %s(%s)",
 		strings.Join(dialect.EscapeAll(fkey.Cols), ", "),
 		dialect.Escape(fkey.RefTable),
 		strings.Join(dialect.EscapeAll(fkey.RefCols), ", "),
@@ -105,16 +144,22 @@ func (fkey ForeignKeyConstraint) String(dialect Dialect) string {
 	return ddl
 }
 
-func checkFKeyCascadeAction(action string) string {
+func checkFKeyCascadeAction(action string) (string, error) {
 	actionUp := strings.ToUpper(action)
 	if actionUp != "" &&
 		actionUp != "CASCADE" &&
 		actionUp != "NO ACTION" &&
 		actionUp != "RESTRICT" &&
 		actionUp != "SET NULL" {
-		panic("Invalid cascading action: " + actionUp)
+		return "", CompileError("Invalid cascading action: %s", actionUp)
 	}
-	return actionUp
+	return actionUp, nil
+}
+
+// Name set the constraint name
+func (fkey ForeignKeyConstraint) Name(name string) ForeignKeyConstraint {
+	fkey.name = name
+	return fkey
 }
 
 // References set the reference part of the foreign key
@@ -125,14 +170,24 @@ func (fkey ForeignKeyConstraint) References(refTable string, refCols ...string)
 }
 
 // OnUpdate set the ON UPDATE action
+// An invalid action makes the table definition fail to compile
 func (fkey ForeignKeyConstraint) OnUpdate(action string) ForeignKeyConstraint {
-	fkey.ActionOnUpdate = checkFKeyCascadeAction(action)
+	var err error
+	fkey.ActionOnUpdate, err = checkFKeyCascadeAction(action)
+	if err != nil {
+		fkey.err = err
+	}
 	return fkey
 }
 
 // OnDelete set the ON DELETE action
+// An invalid action makes the table definition fail to compile
 func (fkey ForeignKeyConstraint) OnDelete(action string) ForeignKeyConstraint {
-	fkey.ActionOnDelete = checkFKeyCascadeAction(action)
+	var err error
+	fkey.ActionOnDelete, err = checkFKeyCascadeAction(action)
+	if err != nil {
+		fkey.err = err
+	}
 	return fkey
 }
 
@@ -152,12 +207,15 @@ type UniqueKeyConstraint struct {
 
 // String generates composite unique indices as sql clause
 func (c UniqueKeyConstraint) String(dialect Dialect) string {
-	return fmt.Sprintf("CONSTRAINT %s UNIQUE(%s)", c.name, strings.Join(dialect.EscapeAll(c.cols), ", "))
+	return fmt.Sprintf("%sUNIQUE(%s)", constraintName(dialect, c.name), strings.Join(dialect.EscapeAll(c.cols), ", "))
 }
 
 // Table optionally set the constraint name based on the table name
 // if a name is already defined, it remains untouched
 func (c UniqueKeyConstraint) Table(name string) UniqueKeyConstraint {
+	if c.name != "" {
+		return c
+	}
 	return c.Name(
 		fmt.Sprintf("u_%s_%s", name, strings.Join(c.cols, "_")),
 	)
@@ -168,3 +226,125 @@ func (c UniqueKeyConstraint) Name(name string) UniqueKeyConstraint {
 	c.name = name
 	return c
 }
+
+// Check generates a table CHECK constraint given a boolean expression
+// Check(Column("end", Date()).Gt(Column("start", Date())))
+func Check(clause Clause) CheckConstraint {
+	return CheckConstraint{clause: clause}
+}
+
+// CheckConstraint is the definition of the check constraints of tables
+type CheckConstraint struct {
+	name   string
+	table  string
+	clause Clause
+	// renames maps the columns referenced by clause to their new names
+	renames map[string]string
+}
+
+// String generates the check constraint as sql clause
+func (c CheckConstraint) String(dialect Dialect) string {
+	expression := literalCompiler{Compiler: dialect.GetCompiler(), renames: c.renames}.compile(dialect, c.table, c.clause)
+	return fmt.Sprintf("%sCHECK (%s)", constraintName(dialect, c.name), expression)
+}
+
+// Name set the constraint name
+func (c CheckConstraint) Name(name string) CheckConstraint {
+	c.name = name
+	return c
+}
+
+// compileExpression compiles the expression of a constraint. The bound values
+// are rendered as literals, and the columns of the table are not qualified
+func compileExpression(dialect Dialect, table string, clause Clause) string {
+	return literalCompiler{Compiler: dialect.GetCompiler()}.compile(dialect, table, clause)
+}
+
+// expressionColumns returns the names of the columns of the table that the
+// expression references, once renamed as given by renames
+func expressionColumns(table string, clause Clause, renames map[string]string) []string {
+	columns := []string{}
+	dialect := NewDefaultDialect()
+	literalCompiler{Compiler: dialect.GetCompiler(), renames: renames, columns: &columns}.compile(dialect, table, clause)
+	return columns
+}
+
+// renameColumn returns the renames of the columns referenced by an
+// expression, updated with the rename of a column of its table
+func renameColumn(renames map[string]string, name string, newName string) map[string]string {
+	updated := map[string]string{name: newName}
+	for original, current := range renames {
+		if current == name {
+			current = newName
+		}
+		updated[original] = current
+	}
+	return updated
+}
+
+// literalCompiler is a compiler that renders the bound values as literals,
+// as DDL statements cannot have bind parameters
+type literalCompiler struct {
+	Compiler
+	// renames maps the columns of the table to their new names
+	renames map[string]string
+	// columns collects the names of the compiled columns of the table
+	columns *[]string
+}
+
+// compile compiles the expression, the columns of the table being not
+// qualified
+func (c literalCompiler) compile(dialect Dialect, table string, clause Clause) string {
+	context := &CompilerContext{
+		dialect:  dialect,
+		compiler: c,
+		binds:    []interface{}{},
+	}
+	context.SetDefaultTableName(table)
+	return clause.Accept(context)
+}
+
+// VisitColumn compiles a column, renamed if it is a column of the table
+// The columns of a column constraint all belong to the table of the column
+func (c literalCompiler) VisitColumn(context Context, column ColumnElem) string {
+	table := context.DefaultTableName()
+	if table == "" || column.Table == "" || column.Table == table {
+		if name, ok := c.renames[column.Name]; ok {
+			column.Name = name
+		}
+		if c.columns != nil {
+			*c.columns = append(*c.columns, column.Name)
+		}
+	}
+	return c.Compiler.VisitColumn(context, column)
+}
+
+// VisitBind renders the value as a literal, quoted by the dialect if it is a
+// StringQuoter
+// The booleans are rendered as 1 and 0 if the dialect lacks FeatureBoolean,
+// and the times as their wall clock time
+func (literalCompiler) VisitBind(context Context, bind BindClause) string {
+	switch value := bind.Value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if !context.Dialect().Features().Has(FeatureBoolean) {
+			if value {
+				return "1"
+			}
+			return "0"
+		}
+		if value {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return "'" + value.Format("2006-01-02 15:04:05.999999") + "'"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%v", value)
+	case []byte:
+		return quoteString(context.Dialect(), string(value))
+	default:
+		return quoteString(context.Dialect(), fmt.Sprintf("%v", value))
+	}
+}
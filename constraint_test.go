@@ -1,8 +1,10 @@
 package qb
 
 import (
-	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestConstraints(t *testing.T) {
@@ -12,7 +14,7 @@ func TestConstraints(t *testing.T) {
 	assert.Equal(t, Constraint("NOT NULL"), NotNull())
 	assert.Equal(t, Constraint("DEFAULT '5'"), Default(5))
 	assert.Equal(t, Constraint("UNIQUE"), Unique())
-	assert.Equal(t, ConstraintElem{"CHECK id > 5"}, Constraint("CHECK id > 5"))
+	assert.Equal(t, ConstraintElem{Name: "CHECK id > 5"}, Constraint("CHECK id > 5"))
 	assert.Equal(t, "NOT NULL", NotNull().String())
 
 	assert.Equal(t, "PRIMARY KEY(id)", PrimaryKey("id").String(dialect))
@@ -25,12 +27,12 @@ func TestConstraints(t *testing.T) {
 		ForeignKey("user_id", "user_email").References("users", "id", "email").String(dialect),
 		"FOREIGN KEY(user_id, user_email) REFERENCES users(id, email)")
 
-	assert.Panics(t, func() {
-		ForeignKey().OnUpdate("invalid")
-	})
-	assert.Panics(t, func() {
-		ForeignKey().OnDelete("invalid")
-	})
+	assert.Equal(t, CompileError("Invalid cascading action: INVALID"), ForeignKey().OnUpdate("invalid").err)
+	assert.Equal(t, CompileError("Invalid cascading action: INVALID"), ForeignKey().OnDelete("invalid").err)
+	assert.Equal(t,
+		CompileError("Invalid cascading action: INVALID"),
+		Table("t", Column("c", Int()), ForeignKey("c").References("t2", "c").OnDelete("invalid")).Build(dialect).Err(),
+	)
 	assert.Equal(t,
 		"\tFOREIGN KEY(user_id) REFERENCES users(id) ON DELETE SET NULL",
 		ForeignKey("user_id").References("users", "id").OnDelete("SET NULL").String(dialect),
@@ -43,4 +45,41 @@ func TestConstraints(t *testing.T) {
 	assert.Equal(t,
 		"CONSTRAINT u_users_id_email UNIQUE(id, email)",
 		UniqueKey("id", "email").Table("users").String(dialect))
+	assert.Equal(t,
+		"CONSTRAINT uniq_email UNIQUE(email)",
+		UniqueKey("email").Name("uniq_email").Table("users").String(dialect))
+}
+
+func TestNamedConstraints(t *testing.T) {
+	dialect := NewDialect("default")
+
+	assert.Equal(t, "CONSTRAINT pk_users PRIMARY KEY(id)", PrimaryKey("id").Name("pk_users").String(dialect))
+	assert.Equal(t,
+		"\tCONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id)",
+		ForeignKey("user_id").Name("fk_user").References("users", "id").String(dialect))
+}
+
+func TestCheckConstraints(t *testing.T) {
+	dialect := NewDialect("default")
+	age := Column("age", Int())
+
+	assert.Equal(t, "CHECK (age >= 18)", Check(age.Gte(18)).String(dialect))
+	assert.Equal(t,
+		"CONSTRAINT adult CHECK ((age >= 18 AND age < 150))",
+		Check(And(age.Gte(18), age.Lt(150))).Name("adult").String(dialect))
+	assert.Equal(t,
+		"CHECK (status IN ('active', 'it''s over', NULL, TRUE, 1.5))",
+		Check(In(Column("status", Varchar()), "active", "it's over", nil, true, 1.5)).String(dialect))
+
+	assert.Equal(t,
+		"CHECK (created_at > '2017-02-28 13:30:15.5')",
+		Check(Column("created_at", Timestamp()).Gt(time.Date(2017, 2, 28, 13, 30, 15, 5e8, time.UTC))).String(dialect))
+	assert.Equal(t,
+		"CHECK (status IN (1, 0))",
+		Check(In(Column("status", Varchar()), true, false)).String(restrictedDialect{dialect, 0}))
+
+	assert.Equal(t, "age INT NOT NULL CHECK (age > 0)", age.NotNull().Check(age.Gt(0)).String(dialect))
+
+	users := Table("users", Column("id", Int()), age, Check(Table("users", age).C("age").Lte(150)))
+	assert.Equal(t, "CHECK (age <= 150)", users.CheckConstraints[0].String(dialect))
 }
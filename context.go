@@ -11,4 +11,6 @@ type Context interface {
 	SetDefaultTableName(name string)
 	InSubQuery() bool
 	SetInSubQuery(inSubQuery bool)
+	AddError(err error)
+	Errors() []error
 }
@@ -0,0 +1,81 @@
+package qb
+
+// With generates a common table expression given its name and query
+// The returned CTEClause must be attached to a select statement with
+// SelectStmt.With() and can then be used as a Selectable in From() and joins.
+func With(name string, query Query) CTEClause {
+	return CTEClause{
+		Name:  name,
+		Query: query,
+	}
+}
+
+// WithRecursive generates a recursive common table expression, which query
+// can refer to the common table expression itself
+// The query is usually a UnionAll() of the non-recursive and recursive terms.
+func WithRecursive(name string, query Query) CTEClause {
+	cte := With(name, query)
+	cte.Recursive = true
+	return cte
+}
+
+// CTEClause is a common table expression (WITH name AS (SELECT ...))
+// It satisfies the Selectable interface, and is compiled as a reference
+// to its name
+type CTEClause struct {
+	Name      string
+	Query     Query
+	Recursive bool
+}
+
+// Accept compiles the reference to the common table expression
+func (c CTEClause) Accept(context Context) string {
+	return context.Compiler().VisitLabel(context, c.Name)
+}
+
+// All returns the columns of the common table expression
+func (c CTEClause) All() []Clause {
+	var clauses []Clause
+	for _, col := range c.ColumnList() {
+		clauses = append(clauses, col)
+	}
+	return clauses
+}
+
+// ColumnList returns the columns of the common table expression query,
+// with their "Table" field set to the common table expression name
+func (c CTEClause) ColumnList() []ColumnElem {
+	var cols []ColumnElem
+	for _, col := range c.Query.ColumnList() {
+		col.Table = c.Name
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// C returns the column of the common table expression with the given name
+// If the select list has no such column, a column with the given name is
+// returned anyway so that columns of raw select lists can be referred to.
+func (c CTEClause) C(name string) ColumnElem {
+	for _, col := range c.ColumnList() {
+		if col.Name == name {
+			return col
+		}
+	}
+	return ColumnElem{Name: name, Table: c.Name}
+}
+
+// DefaultName returns the common table expression name
+func (c CTEClause) DefaultName() string {
+	return c.Name
+}
+
+// WithClause is the WITH clause of a select statement
+type WithClause struct {
+	CTEs []CTEClause
+}
+
+// Accept calls the compiler VisitWith method
+func (c WithClause) Accept(context Context) string {
+	return context.Compiler().VisitWith(context, c)
+}
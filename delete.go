@@ -4,28 +4,40 @@ package qb
 // qb.Delete(usersTable).Where(qb.Eq("id", 5))
 func Delete(table TableElem) DeleteStmt {
 	return DeleteStmt{
-		table:     table,
-		returning: []ColumnElem{},
+		Table:         table,
+		ReturningCols: []ColumnElem{},
 	}
 }
 
 // DeleteStmt is the base struct for building delete queries
 type DeleteStmt struct {
-	table     TableElem
-	where     *WhereClause
-	returning []ColumnElem
+	Table         TableElem
+	UsingTables   []Selectable
+	WhereClause   *WhereClause
+	ReturningCols []ColumnElem
+}
+
+// Using adds tables the delete statement can refer to in its where clause,
+// making it a multi-table delete. It is compiled as DELETE ... USING on
+// postgres, as DELETE t1 FROM t1 JOIN ... on mysql and with a
+// WHERE EXISTS(...) subquery on sqlite.
+// The join conditions are given in the where clause
+// Delete(sessions).Using(users).Where(sessions.C("user_id").Eq(users.C("id")))
+func (s DeleteStmt) Using(tables ...Selectable) DeleteStmt {
+	s.UsingTables = append(s.UsingTables, tables...)
+	return s
 }
 
 // Where adds a where clause to the current delete statement
 func (s DeleteStmt) Where(clause Clause) DeleteStmt {
-	s.where = &WhereClause{clause}
+	s.WhereClause = &WhereClause{clause}
 	return s
 }
 
 // Returning accepts the column names as strings and forms the returning array of insert statement
-// NOTE: Please use it in only postgres dialect, otherwise it'll crash
+// Building the statement fails with ErrUnsupported if the dialect does not support RETURNING
 func (s DeleteStmt) Returning(cols ...ColumnElem) DeleteStmt {
-	s.returning = append(s.returning, cols...)
+	s.ReturningCols = append(s.ReturningCols, cols...)
 	return s
 }
 
@@ -40,6 +52,7 @@ func (s DeleteStmt) Build(dialect Dialect) *Stmt {
 	statement := Statement()
 	statement.AddSQLClause(s.Accept(context))
 	statement.AddBinding(context.Binds()...)
+	statement.AddError(context.Errors()...)
 
 	return statement
 }
@@ -34,3 +34,26 @@ func TestDelete(t *testing.T) {
 	statement = Delete(users).Build(dialect)
 	assert.Equal(t, "DELETE FROM users;", statement.SQL())
 }
+
+func TestDeleteUsing(t *testing.T) {
+	dialect := NewDialect("default")
+
+	users := Table(
+		"users",
+		Column("id", Varchar().Size(36)),
+		Column("active", Boolean()),
+	)
+	sessions := Table(
+		"sessions",
+		Column("id", Varchar().Size(36)),
+		Column("user_id", Varchar().Size(36)),
+	)
+
+	statement := Delete(sessions).
+		Using(users).
+		Where(And(sessions.C("user_id").Eq(users.C("id")), users.C("active").Eq(false))).
+		Build(dialect)
+
+	assert.Equal(t, "DELETE FROM sessions\nUSING users\nWHERE (sessions.user_id = users.id AND users.active = ?);", statement.SQL())
+	assert.Equal(t, []interface{}{false}, statement.Bindings())
+}
@@ -1,12 +1,31 @@
 package qb
 
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
 // NewDialect returns a dialect pointer given driver
+// It panics if no dialect is registered for the driver
+//
+// Deprecated: use GetDialect, which returns an error instead
 func NewDialect(driver string) Dialect {
+	dialect, err := GetDialect(driver)
+	if err != nil {
+		panic(err.Error())
+	}
+	return dialect
+}
+
+// GetDialect returns a dialect pointer given driver, or an ErrInterface
+// Error if no dialect is registered for the driver
+func GetDialect(driver string) (Dialect, error) {
 	dialect, ok := DialectRegistry[driver]
-	if ok {
-		return dialect
+	if !ok {
+		return nil, Error{Code: ErrInterface, Orig: errors.New("No such dialect: " + driver)}
 	}
-	panic("No such dialect: " + driver)
+	return dialect, nil
 }
 
 // DialectRegistry is a global registry of dialects
@@ -28,10 +47,36 @@ type Dialect interface {
 	Escaping() bool
 	AutoIncrement(column *ColumnElem) string
 	SupportsUnsigned() bool
+	MaxBindParams() int
+	MaxPacketSize() int
+	Features() Feature
 	Driver() string
 	WrapError(err error) Error
 }
 
+// IndexInspector is implemented by the dialects lacking FeatureIndexIfExists
+// MetaDataElem.CreateAll then skips the existing indexes with IndexExists
+// rather than creating them with IF NOT EXISTS
+type IndexInspector interface {
+	IndexExists(ctx context.Context, conn Conn, table string, name string) (bool, error)
+}
+
+// StringQuoter is implemented by the dialects escaping more than the single
+// quotes in the string literals of the DDL statements, such as MySQL treating
+// the backslash as an escape character
+type StringQuoter interface {
+	QuoteString(str string) string
+}
+
+// quoteString quotes the string literal with the dialect StringQuoter if it
+// implements it, doubling the single quotes otherwise
+func quoteString(dialect Dialect, str string) string {
+	if quoter, ok := dialect.(StringQuoter); ok {
+		return quoter.QuoteString(str)
+	}
+	return "'" + strings.Replace(str, "'", "''", -1) + "'"
+}
+
 // EscapeAll common escape all
 func EscapeAll(dialect Dialect, strings []string) []string {
 	for k, v := range strings {
@@ -53,6 +53,16 @@ func (d *DefaultDialect) AutoIncrement(column *ColumnElem) string {
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *DefaultDialect) SupportsUnsigned() bool { return false }
 
+// MaxBindParams returns the maximum number of bind parameters of a statement
+func (d *DefaultDialect) MaxBindParams() int { return 999 }
+
+// MaxPacketSize returns the maximum size in bytes of a statement and its bind
+// parameters, 0 meaning no limit
+func (d *DefaultDialect) MaxPacketSize() int { return 0 }
+
+// Features returns the set of features supported by the dialect
+func (d *DefaultDialect) Features() Feature { return AllFeatures }
+
 // Driver returns the current driver of dialect
 func (d *DefaultDialect) Driver() string {
 	return ""
@@ -32,4 +32,12 @@ func TestGetDialect(t *testing.T) {
 	assert.Panics(t, func() {
 		NewDialect("unknown")
 	})
+
+	dialect, err := GetDialect("unknown")
+	assert.Nil(t, dialect)
+	assert.Equal(t, ErrInterface, err.(Error).Code)
+
+	dialect, err = GetDialect("default")
+	assert.Nil(t, err)
+	assert.Equal(t, "", dialect.Driver())
 }
@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -15,16 +16,30 @@ const ()
 
 // Dialect is a type of dialect that can be used with mysql driver
 type Dialect struct {
-	escaping bool
+	escaping      bool
+	mariadb       bool
+	maxPacketSize int
 }
 
+// defaultMaxPacketSize is the default max_allowed_packet of the mysql driver
+const defaultMaxPacketSize = 4 << 20
+
 // NewDialect returns a new MysqlDialect
 func NewDialect() qb.Dialect {
-	return &Dialect{false}
+	return &Dialect{}
+}
+
+// NewMariaDBDialect returns a new MysqlDialect for MariaDB servers, which
+// support RETURNING in insert and delete statements. MariaDB servers are
+// accessed through the mysql driver
+// engine, err := qb.NewWithDialect("mysql", dsn, mysql.NewMariaDBDialect())
+func NewMariaDBDialect() qb.Dialect {
+	return &Dialect{mariadb: true}
 }
 
 func init() {
 	qb.RegisterDialect("mysql", NewDialect())
+	qb.RegisterDialect("mariadb", NewMariaDBDialect())
 }
 
 // CompileType compiles a type into its DDL
@@ -71,8 +86,45 @@ func (d *Dialect) AutoIncrement(column *qb.ColumnElem) string {
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *Dialect) SupportsUnsigned() bool { return true }
 
+// MaxBindParams returns the maximum number of placeholders of a prepared statement
+func (d *Dialect) MaxBindParams() int { return 65535 }
+
+// MaxPacketSize returns the maximum size in bytes of a statement and its bind
+// parameters, which is the max_allowed_packet limit of the connection. It is
+// 4MB, the default of the driver, unless set with SetMaxPacketSize
+func (d *Dialect) MaxPacketSize() int {
+	if d.maxPacketSize == 0 {
+		return defaultMaxPacketSize
+	}
+	return d.maxPacketSize
+}
+
+// SetMaxPacketSize sets the max_allowed_packet limit of the connection, which
+// bounds the size of the batch inserts
+// engine.Dialect().(*mysql.Dialect).SetMaxPacketSize(64 << 20)
+func (d *Dialect) SetMaxPacketSize(size int) {
+	d.maxPacketSize = size
+}
+
+// Features returns the set of features supported by the dialect, assuming
+// MySQL 8.0 or MariaDB 10.5 and later
+// MariaDB supports RETURNING in insert and delete statements but not in
+// update statements, and does not support FOR UPDATE OF
+func (d *Dialect) Features() qb.Feature {
+	features := qb.FeatureForUpdate | qb.FeatureRightJoin | qb.FeatureWindow |
+		qb.FeatureCTE | qb.FeatureUpdateFrom | qb.FeatureAutoIncrement |
+		qb.FeatureIndexMethod | qb.FeatureBoolean | qb.FeatureAlterConstraint
+	if d.mariadb {
+		return features | qb.FeatureReturning | qb.FeatureIntersect | qb.FeatureIndexIfExists
+	}
+	return features | qb.FeatureForUpdateOf | qb.FeatureExpressionIndex
+}
+
 // Driver returns the current driver of dialect
 func (d *Dialect) Driver() string {
+	if d.mariadb {
+		return "mariadb"
+	}
 	return "mysql"
 }
 
@@ -81,6 +133,27 @@ func (d *Dialect) GetCompiler() qb.Compiler {
 	return MysqlCompiler{qb.NewSQLCompiler(d)}
 }
 
+// IndexExists returns true if the table of the current database has an index
+// of the given name
+func (d *Dialect) IndexExists(ctx context.Context, conn qb.Conn, table string, name string) (bool, error) {
+	rows, err := conn.QueryContext(ctx,
+		"SELECT 1 FROM information_schema.statistics "+
+			"WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ? LIMIT 1",
+		table, name)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	exists := rows.Next()
+	return exists, rows.Err()
+}
+
+// QuoteString quotes a string literal, doubling the backslashes as well as
+// the single quotes
+func (d *Dialect) QuoteString(str string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(str) + "'"
+}
+
 // WrapError wraps a native error in a qb Error
 func (d *Dialect) WrapError(err error) qb.Error {
 	qbErr := qb.Error{Orig: err}
@@ -138,37 +211,282 @@ type MysqlCompiler struct {
 	qb.SQLCompiler
 }
 
+// VisitExcluded compiles a reference to a value proposed for insertion as
+// VALUES(<column>)
+func (c MysqlCompiler) VisitExcluded(context qb.Context, excluded qb.ExcludedClause) string {
+	return fmt.Sprintf("VALUES(%s)", context.Dialect().Escape(excluded.Column.Name))
+}
+
+// visitCompound compiles the compound select with the base compiler, its
+// LIMIT and OFFSET being rendered by limitOffset
+func (c MysqlCompiler) visitCompound(context qb.Context, compound qb.CompoundClause) string {
+	limit, offset := compound.LimitValue, compound.OffsetValue
+	compound.LimitValue, compound.OffsetValue = nil, nil
+	sql := c.SQLCompiler.VisitCompound(context, compound)
+	if limitOffset := c.limitOffset(limit, offset); limitOffset != "" {
+		sql += "\n" + limitOffset
+	}
+	return sql
+}
+
+// VisitCompound emulates INTERSECT and EXCEPT, which are not supported
+// before MySQL 8.0.31, with (NOT) EXISTS subqueries using null-safe
+// comparisons. MariaDB supports them natively.
+// An ErrUnsupported error is recorded if the column names of the select
+// statements cannot be determined, as well as for INTERSECT ALL and
+// EXCEPT ALL.
+func (c MysqlCompiler) VisitCompound(context qb.Context, compound qb.CompoundClause) string {
+	if c.Dialect.Features().Has(qb.FeatureIntersect) {
+		return c.visitCompound(context, compound)
+	}
+
+	var exists string
+	switch compound.Operator {
+	case "INTERSECT":
+		exists = "EXISTS"
+	case "EXCEPT":
+		exists = "NOT EXISTS"
+	default:
+		return c.visitCompound(context, compound)
+	}
+
+	var names [][]string
+	for _, sel := range compound.Selects {
+		var colNames []string
+		for _, clause := range sel.SelectList {
+			col, ok := clause.(qb.ColumnElem)
+			if !ok {
+				return c.visitCompound(context, compound)
+			}
+			colNames = append(colNames, col.Name)
+		}
+		if len(colNames) == 0 || (len(names) > 0 && len(colNames) != len(names[0])) {
+			return c.visitCompound(context, compound)
+		}
+		names = append(names, colNames)
+	}
+
+	defaultTableName := context.DefaultTableName()
+	inSubQuery := context.InSubQuery()
+	defer func() {
+		context.SetDefaultTableName(defaultTableName)
+		context.SetInSubQuery(inSubQuery)
+	}()
+
+	escape := context.Dialect().Escape
+	first := escape("qb_compound")
+	context.SetInSubQuery(false)
+	lines := []string{fmt.Sprintf(
+		"SELECT DISTINCT *\nFROM (%s) AS %s",
+		compound.Selects[0].Accept(context),
+		first,
+	)}
+
+	var conditions []string
+	for i, sel := range compound.Selects[1:] {
+		alias := escape(fmt.Sprintf("qb_compound_%d", i+1))
+		var equals []string
+		for j, name := range names[i+1] {
+			equals = append(equals, fmt.Sprintf(
+				"%s.%s <=> %s.%s",
+				alias, escape(name), first, escape(names[0][j]),
+			))
+		}
+		context.SetInSubQuery(false)
+		conditions = append(conditions, fmt.Sprintf(
+			"%s(SELECT 1 FROM (%s) AS %s WHERE %s)",
+			exists, sel.Accept(context), alias, strings.Join(equals, " AND "),
+		))
+	}
+	if len(conditions) > 0 {
+		lines = append(lines, "WHERE "+strings.Join(conditions, " AND "))
+	}
+
+	context.SetDefaultTableName("")
+	if compound.OrderByClause != nil {
+		lines = append(lines, compound.OrderByClause.Accept(context))
+	}
+	if sql := c.limitOffset(compound.LimitValue, compound.OffsetValue); sql != "" {
+		lines = append(lines, sql)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// limitOffset compiles the LIMIT and OFFSET clauses, if any
+// MySQL has no OFFSET without LIMIT, the largest limit is then given
+func (c MysqlCompiler) limitOffset(limit *int, offset *int) string {
+	sql := c.LimitOffset(limit, offset)
+	if limit == nil && offset != nil {
+		sql = "LIMIT 18446744073709551615 " + sql
+	}
+	return sql
+}
+
+// VisitUpdate compiles a multi-table update as UPDATE ... JOIN ... SET ...
+// All the columns are then qualified, the join conditions being in the where
+// clause.
+// Neither mysql nor MariaDB support RETURNING in update statements
+func (c MysqlCompiler) VisitUpdate(context qb.Context, update qb.UpdateStmt) string {
+	if len(update.ReturningCols) != 0 {
+		context.AddError(qb.Error{
+			Code: qb.ErrUnsupported,
+			Orig: fmt.Errorf("RETURNING is not supported in update statements by the %s dialect", c.Dialect.Driver()),
+		})
+		update.ReturningCols = nil
+	}
+	if len(update.FromTables) == 0 {
+		return c.SQLCompiler.VisitUpdate(context, update)
+	}
+
+	lines := []string{"UPDATE " + update.Table.Accept(context)}
+	for _, t := range update.FromTables {
+		lines = append(lines, "JOIN "+t.Accept(context))
+	}
+
+	sets := qb.List()
+	for _, k := range update.ColumnNames() {
+		sets.Clauses = append(sets.Clauses,
+			qb.BinaryExpression(update.Table.C(k), "=", qb.GetClauseFrom(update.ValuesMap[k])))
+	}
+	if len(sets.Clauses) > 0 {
+		lines = append(lines, "SET "+sets.Accept(context))
+	}
+
+	if update.WhereClause != nil {
+		lines = append(lines, update.WhereClause.Accept(context))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// VisitDelete compiles a multi-table delete as DELETE t1 FROM t1 JOIN ...
+// MariaDB supports RETURNING in single-table deletes only
+func (c MysqlCompiler) VisitDelete(context qb.Context, delete qb.DeleteStmt) string {
+	if len(delete.UsingTables) == 0 {
+		return c.SQLCompiler.VisitDelete(context, delete)
+	}
+	if len(delete.ReturningCols) != 0 {
+		context.AddError(qb.Error{
+			Code: qb.ErrUnsupported,
+			Orig: fmt.Errorf("RETURNING is not supported in multi-table deletes by the %s dialect", c.Dialect.Driver()),
+		})
+	}
+
+	table := delete.Table.Accept(context)
+	lines := []string{"DELETE " + table, "FROM " + table}
+	for _, t := range delete.UsingTables {
+		lines = append(lines, "JOIN "+t.Accept(context))
+	}
+
+	if delete.WhereClause != nil {
+		lines = append(lines, delete.WhereClause.Accept(context))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 // VisitUpsert generates INSERT INTO ... VALUES ... ON DUPLICATE KEY UPDATE ...
-func (MysqlCompiler) VisitUpsert(context qb.Context, upsert qb.UpsertStmt) string {
+// The conflict target is ignored, as mysql checks all the unique keys.
+// DoNothing() is compiled as a no-op update of the first conflict target
+// column, and the update where clause as IF(<where>, <value>, <column>)
+// expressions. As mysql assigns the columns from left to right, the where
+// clause should not refer to the updated columns.
+// RETURNING is only supported by MariaDB
+func (c MysqlCompiler) VisitUpsert(context qb.Context, upsert qb.UpsertStmt) string {
+	context.SetDefaultTableName(upsert.Table.Name)
+	defer func() { context.SetDefaultTableName("") }()
+
 	var (
 		colNames []string
 		values   []string
 	)
 
-	for k, v := range upsert.ValuesMap {
-		colNames = append(colNames, context.Compiler().VisitLabel(context, k))
-		// context.Binds = append(context.Binds, v)
-		context.AddBinds(v)
-		values = append(values, "?")
+	names := upsert.ColumnNames()
+	for _, k := range names {
+		colNames = append(colNames, upsert.Table.C(k).Accept(context))
+		values = append(values, qb.GetClauseFrom(upsert.ValuesMap[k]).Accept(context))
 	}
 
 	updates := []string{}
-	for k, v := range upsert.ValuesMap {
-		updates = append(updates, fmt.Sprintf(
-			"%s = %s",
-			context.Dialect().Escape(k),
-			"?",
-		))
-		context.AddBinds(v)
+	if !upsert.IgnoreConflict {
+		for _, v := range upsert.UpdateSet() {
+			column := upsert.Table.C(v.Name).Accept(context)
+			value := qb.GetClauseFrom(v.Value).Accept(context)
+			if upsert.UpdateWhere != nil {
+				value = fmt.Sprintf("IF(%s, %s, %s)", upsert.UpdateWhere.Clause().Accept(context), value, column)
+			}
+			updates = append(updates, fmt.Sprintf("%s = %s", column, value))
+		}
+	}
+	if len(updates) == 0 {
+		var column string
+		if target := upsert.ConflictTarget(); len(target) > 0 {
+			column = target[0].Name
+		} else if len(names) > 0 {
+			column = names[0]
+		}
+		column = context.Dialect().Escape(column)
+		updates = append(updates, fmt.Sprintf("%s = %s", column, column))
 	}
 
 	sql := fmt.Sprintf(
 		"INSERT INTO %s(%s)\nVALUES(%s)\nON DUPLICATE KEY UPDATE %s",
-		context.Dialect().Escape(upsert.Table.Name),
+		upsert.Table.Accept(context),
 		strings.Join(colNames, ", "),
 		strings.Join(values, ", "),
 		strings.Join(updates, ", "),
 	)
+	sql += c.CompileReturning(context, upsert.ReturningCols)
 
 	return sql
 }
+
+// CompileAlterTable compiles an ALTER TABLE statement, its actions being
+// combined in as few statements as possible
+// The type and nullability of a column are changed with MODIFY COLUMN, which
+// takes the whole column definition. The constraints are dropped with
+// DROP PRIMARY KEY, DROP INDEX (unique constraints), DROP FOREIGN KEY or
+// DROP CONSTRAINT (check constraints)
+func (c MysqlCompiler) CompileAlterTable(context qb.Context, alter qb.AlterTableStmt) []string {
+	return c.CombineAlterActions(context, alter, func(table qb.TableElem, action qb.AlterAction) string {
+		altered := action.Apply(table)
+		switch action.Op {
+		case qb.AlterColumnType, qb.AlterSetNotNull, qb.AlterDropNotNull:
+			col := altered.C(action.Name)
+			// the primary key is left untouched by MODIFY COLUMN
+			col.Options.InlinePrimaryKey = false
+			return "MODIFY COLUMN " + col.String(c.Dialect)
+		case qb.AlterDropConstraint:
+			switch {
+			case len(altered.PrimaryKeyConstraint.Columns) != len(table.PrimaryKeyConstraint.Columns):
+				return "DROP PRIMARY KEY"
+			case len(altered.UniqueKeyConstraints) != len(table.UniqueKeyConstraints):
+				return "DROP INDEX " + c.Dialect.Escape(action.Name)
+			case len(altered.ForeignKeyConstraints.FKeys) != len(table.ForeignKeyConstraints.FKeys):
+				return "DROP FOREIGN KEY " + c.Dialect.Escape(action.Name)
+			}
+		}
+		return c.CompileAlterClause(context, table, action)
+	})
+}
+
+// VisitIndex compiles a CREATE INDEX statement
+// The index method is given after the indexed columns. MySQL does not support
+// CREATE INDEX IF NOT EXISTS, MetaDataElem.CreateAll looks up the existing
+// indexes with IndexExists instead
+func (c MysqlCompiler) VisitIndex(context qb.Context, index qb.IndexElem) string {
+	method := index.Method
+	index.Method = ""
+	create := c.SQLCompiler.VisitIndex(context, index)
+	if method != "" {
+		create += " USING " + strings.ToUpper(method)
+	}
+	return create
+}
+
+// VisitDropIndex compiles a DROP INDEX statement, which is given the table of
+// the index. MySQL does not support DROP INDEX IF EXISTS
+func (c MysqlCompiler) VisitDropIndex(context qb.Context, drop qb.DropIndexStmt) string {
+	return fmt.Sprintf("%s ON %s", c.SQLCompiler.VisitDropIndex(context, drop), c.Dialect.Escape(drop.Table))
+}
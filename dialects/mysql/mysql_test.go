@@ -1,9 +1,11 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -42,6 +44,13 @@ func (suite *MysqlTestSuite) TestUUID() {
 	assert.Equal(suite.T(), "VARCHAR(36)", suite.engine.Dialect().CompileType(qb.UUID()))
 }
 
+func (suite *MysqlTestSuite) TestMaxPacketSize() {
+	dialect := NewDialect()
+	assert.Equal(suite.T(), 4<<20, dialect.MaxPacketSize())
+	dialect.(*Dialect).SetMaxPacketSize(64 << 20)
+	assert.Equal(suite.T(), 64<<20, dialect.MaxPacketSize())
+}
+
 func (suite *MysqlTestSuite) TestDialect() {
 	dialect := qb.NewDialect("mysql")
 	assert.Equal(suite.T(), true, dialect.SupportsUnsigned())
@@ -52,6 +61,15 @@ func (suite *MysqlTestSuite) TestDialect() {
 	assert.Equal(suite.T(), "`test`", dialect.Escape("test"))
 	assert.Equal(suite.T(), []string{"`test`"}, dialect.EscapeAll([]string{"test"}))
 	assert.Equal(suite.T(), "mysql", dialect.Driver())
+	assert.Equal(suite.T(), false, dialect.Features().Has(qb.FeatureReturning))
+
+	mariadb := qb.NewDialect("mariadb")
+	assert.Equal(suite.T(), true, mariadb.Features().Has(qb.FeatureReturning))
+	assert.Equal(suite.T(), "mariadb", mariadb.Driver())
+
+	engine, err := qb.NewWithDialect("mysql", mysqlDsn, NewMariaDBDialect())
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "mariadb", engine.Driver())
 }
 
 func (suite *MysqlTestSuite) TestWrapError() {
@@ -228,10 +246,329 @@ func (suite *MysqlTestSuite) TestUpsert() {
 	assert.Contains(suite.T(), sql, "id", "email", "created_at")
 	assert.Contains(suite.T(), sql, "VALUES(?, ?, ?)")
 	assert.Contains(suite.T(), sql, "ON DUPLICATE KEY UPDATE")
-	assert.Contains(suite.T(), sql, "id = ?", "email = ?", "created_at = ?")
+	assert.Contains(suite.T(), sql, "email = VALUES(email)", "created_at = VALUES(created_at)")
 	assert.Contains(suite.T(), binds, "9883cf81-3b56-4151-ae4e-3903c5bc436d")
 	assert.Contains(suite.T(), binds, "al@pacino.com")
-	assert.Equal(suite.T(), 6, len(binds))
+	assert.Equal(suite.T(), 3, len(binds))
+	assert.Equal(suite.T(), "INSERT INTO users(id, email, created_at)\nVALUES(?, ?, ?)\nON DUPLICATE KEY UPDATE email = VALUES(email), created_at = VALUES(created_at)", sql)
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Upsert(users).
+		Values(map[string]interface{}{"id": "1", "email": "al@pacino.com"}).
+		OnConflict(users.C("email")).
+		DoUpdate(qb.Value("created_at", qb.SQLText("NOW()"))).
+		DoUpdateWhere(users.C("email").NotEq("")).
+		Accept(ctx)
+	assert.Equal(suite.T(), "INSERT INTO users(id, email)\nVALUES(?, ?)\nON DUPLICATE KEY UPDATE created_at = IF(email != ?, NOW(), created_at)", sql)
+	assert.Equal(suite.T(), []interface{}{"1", "al@pacino.com", ""}, ctx.Binds())
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Upsert(users).
+		Values(map[string]interface{}{"id": "1", "email": "al@pacino.com"}).
+		DoNothing().
+		Accept(ctx)
+	assert.Equal(suite.T(), "INSERT INTO users(id, email)\nVALUES(?, ?)\nON DUPLICATE KEY UPDATE id = id", sql)
+}
+
+func (suite *MysqlTestSuite) TestCompound() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+	)
+	admins := qb.Table(
+		"admins",
+		qb.Column("user_id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+	)
+
+	s1 := qb.Select(users.C("id"), users.C("email")).From(users).Where(users.C("id").Gt(5))
+	s2 := qb.Select(admins.C("user_id"), admins.C("email")).From(admins)
+
+	ctx := qb.NewCompilerContext(NewDialect())
+	intersect := qb.Intersect(s1, s2)
+	sql := intersect.OrderBy(intersect.C("email")).Limit(10).Accept(ctx)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT DISTINCT *",
+		"FROM (SELECT id, email",
+		"FROM users",
+		"WHERE id > ?) AS qb_compound",
+		"WHERE EXISTS(SELECT 1 FROM (SELECT user_id, email",
+		"FROM admins) AS qb_compound_1 WHERE qb_compound_1.user_id <=> qb_compound.id AND qb_compound_1.email <=> qb_compound.email)",
+		"ORDER BY email ASC",
+		"LIMIT 10",
+	}, "\n"), sql)
+	assert.Equal(suite.T(), []interface{}{5}, ctx.Binds())
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Except(s1, s2).Accept(ctx)
+	assert.Contains(suite.T(), sql, "WHERE NOT EXISTS(SELECT 1 FROM (SELECT user_id, email")
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Except(s1, s2).Offset(5).Accept(ctx)
+	assert.True(suite.T(), strings.HasSuffix(sql, "\nLIMIT 18446744073709551615 OFFSET 5"))
+
+	// the emulation needs the column names
+	err := qb.Except(s1, qb.Select(qb.SQLText("1"), qb.SQLText("2"))).Build(NewDialect()).Err()
+	assert.Equal(suite.T(), qb.NotSupportedError(NewDialect(), qb.FeatureIntersect), err)
+	err = qb.Compound("INTERSECT ALL", s1, s2).Build(NewDialect()).Err()
+	assert.Equal(suite.T(), qb.NotSupportedError(NewDialect(), qb.FeatureIntersectAll), err)
+
+	ctx = qb.NewCompilerContext(NewMariaDBDialect())
+	sql = qb.Except(s1, qb.Select(qb.SQLText("1"), qb.SQLText("2"))).Accept(ctx)
+	assert.Equal(suite.T(), "SELECT id, email\nFROM users\nWHERE id > ?\nEXCEPT\nSELECT 1, 2", sql)
+	assert.Empty(suite.T(), ctx.Errors())
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Union(s1, s2).Accept(ctx)
+	assert.Equal(suite.T(), "SELECT id, email\nFROM users\nWHERE id > ?\nUNION\nSELECT user_id, email\nFROM admins", sql)
+
+	// the bare OFFSET is rendered the MySQL way on every path
+	for _, dialect := range []qb.Dialect{NewDialect(), NewMariaDBDialect()} {
+		ctx = qb.NewCompilerContext(dialect)
+		sql = qb.Union(s1, s2).Offset(5).Accept(ctx)
+		assert.Equal(suite.T(), "SELECT id, email\nFROM users\nWHERE id > ?\nUNION\nSELECT user_id, email\nFROM admins\nLIMIT 18446744073709551615 OFFSET 5", sql)
+		ctx = qb.NewCompilerContext(dialect)
+		sql = qb.UnionAll(s1, s2).Limit(10).Offset(5).Accept(ctx)
+		assert.True(suite.T(), strings.HasSuffix(sql, "\nLIMIT 10 OFFSET 5"))
+	}
+}
+
+func (suite *MysqlTestSuite) TestMultiTable() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+	)
+	sessions := qb.Table(
+		"sessions",
+		qb.Column("id", qb.Int()),
+		qb.Column("user_id", qb.Int()),
+	)
+
+	ctx := qb.NewCompilerContext(NewDialect())
+	sql := qb.Update(users).
+		Values(map[string]interface{}{"email": "al@pacino.com"}).
+		From(sessions).
+		Where(qb.And(users.C("id").Eq(sessions.C("user_id")), sessions.C("id").Eq(5))).
+		Accept(ctx)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"UPDATE users",
+		"JOIN sessions",
+		"SET users.email = ?",
+		"WHERE (users.id = sessions.user_id AND sessions.id = ?)",
+	}, "\n"), sql)
+	assert.Equal(suite.T(), []interface{}{"al@pacino.com", 5}, ctx.Binds())
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Delete(sessions).
+		Using(users).
+		Where(sessions.C("user_id").Eq(users.C("id"))).
+		Accept(ctx)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"DELETE sessions",
+		"FROM sessions",
+		"JOIN users",
+		"WHERE sessions.user_id = users.id",
+	}, "\n"), sql)
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Update(users).Values(map[string]interface{}{"email": "al@pacino.com"}).Accept(ctx)
+	assert.Equal(suite.T(), "UPDATE users\nSET email = ?", sql)
+}
+
+func (suite *MysqlTestSuite) TestILike() {
+	email := qb.Column("email", qb.Varchar())
+
+	ctx := qb.NewCompilerContext(NewDialect())
+	assert.Equal(suite.T(), "LOWER(email) LIKE LOWER(?)", qb.ILike(email, "al@%").Accept(ctx))
+	assert.Equal(suite.T(), []interface{}{"al@%"}, ctx.Binds())
+}
+
+func (suite *MysqlTestSuite) TestReturning() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+	)
+
+	ctx := qb.NewCompilerContext(NewDialect())
+	sql := qb.Insert(users).
+		Values(map[string]interface{}{"email": "al@pacino.com"}).
+		Returning(users.C("id")).
+		Accept(ctx)
+	assert.Equal(suite.T(), "INSERT INTO users(email)\nVALUES(?)", sql)
+
+	ctx = qb.NewCompilerContext(NewMariaDBDialect())
+	sql = qb.Insert(users).
+		Values(map[string]interface{}{"email": "al@pacino.com"}).
+		Returning(users.C("id")).
+		Accept(ctx)
+	assert.Equal(suite.T(), "INSERT INTO users(email)\nVALUES(?)\nRETURNING id", sql)
+
+	ctx = qb.NewCompilerContext(NewMariaDBDialect())
+	sql = qb.Delete(users).
+		Where(users.C("id").Eq(5)).
+		Returning(users.C("email")).
+		Accept(ctx)
+	assert.Equal(suite.T(), "DELETE FROM users\nWHERE users.id = ?\nRETURNING email", sql)
+
+	for _, dialect := range []qb.Dialect{NewDialect(), NewMariaDBDialect()} {
+		err := qb.Update(users).
+			Values(map[string]interface{}{"email": "al@pacino.com"}).
+			Returning(users.C("id")).
+			Build(dialect).Err()
+		assert.Equal(suite.T(), qb.ErrUnsupported, err.(qb.Error).Code)
+	}
+
+	upsert := qb.Upsert(users).
+		Values(map[string]interface{}{"id": 1, "email": "al@pacino.com"}).
+		Returning(users.C("id"))
+	err := upsert.Build(NewDialect()).Err()
+	assert.Equal(suite.T(), qb.NotSupportedError(NewDialect(), qb.FeatureReturning), err)
+	statement := upsert.Build(NewMariaDBDialect())
+	assert.Nil(suite.T(), statement.Err())
+	assert.Equal(suite.T(),
+		"INSERT INTO users(id, email)\nVALUES(?, ?)\nON DUPLICATE KEY UPDATE email = VALUES(email)\nRETURNING id;",
+		statement.SQL())
+
+	sessions := qb.Table("sessions", qb.Column("user_id", qb.Int()))
+	for _, dialect := range []qb.Dialect{NewDialect(), NewMariaDBDialect()} {
+		err := qb.Delete(users).
+			Using(sessions).
+			Where(sessions.C("user_id").Eq(users.C("id"))).
+			Returning(users.C("id")).
+			Build(dialect).Err()
+		assert.Equal(suite.T(), qb.ErrUnsupported, err.(qb.Error).Code)
+	}
+}
+
+func (suite *MysqlTestSuite) TestAlterTable() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()).NotNull(),
+		qb.Column("name", qb.Varchar()),
+		qb.UniqueKey("email", "name"),
+	)
+
+	sql := qb.AlterTable(users).
+		AlterColumnType("name", qb.Text()).
+		SetNotNull("name").
+		DropNotNull("email").
+		AlterColumnType("id", qb.BigInt()).
+		DropConstraint("u_users_email_name").
+		AddIndex(qb.Index("", "name")).
+		DropIndex("i_users_name").
+		Build(NewDialect()).SQL()
+	assert.Equal(suite.T(), strings.Join([]string{
+		"ALTER TABLE users MODIFY COLUMN name TEXT",
+		"ALTER TABLE users MODIFY COLUMN name TEXT NOT NULL, MODIFY COLUMN email VARCHAR(255), " +
+			"MODIFY COLUMN id BIGINT AUTO_INCREMENT, DROP INDEX u_users_email_name",
+		"CREATE INDEX i_users_name ON users(name)",
+		"DROP INDEX i_users_name ON users;",
+	}, ";\n"), sql)
+}
+
+func (suite *MysqlTestSuite) TestDropConstraint() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("team_id", qb.Int()),
+		qb.PrimaryKey("id").Name("pk_users"),
+		qb.UniqueKey("email").Name("uniq_email"),
+		qb.ForeignKey("team_id").References("teams", "id").Name("fk_team"),
+		qb.Check(qb.Column("id", qb.Int()).Gt(0)).Name("positive_id"),
+	)
+
+	sql := qb.AlterTable(users).
+		DropConstraint("pk_users").
+		DropConstraint("uniq_email").
+		DropConstraint("fk_team").
+		DropConstraint("positive_id").
+		Build(NewDialect()).SQL()
+	assert.Equal(suite.T(),
+		"ALTER TABLE users DROP PRIMARY KEY, DROP INDEX uniq_email, DROP FOREIGN KEY fk_team, DROP CONSTRAINT positive_id;",
+		sql)
+}
+
+func (suite *MysqlTestSuite) TestDDLOptions() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+		qb.Index("users", "email"),
+	)
+
+	index := users.Indices[0]
+	index.IfNotExists = true
+	assert.Equal(suite.T(), qb.NotSupportedError(NewDialect(), qb.FeatureIndexIfExists), index.Build(NewDialect()).Err())
+	assert.Equal(suite.T(),
+		"CREATE INDEX IF NOT EXISTS i_users_email ON users(email)",
+		users.Indices[0].Create(NewMariaDBDialect(), qb.IfNotExists))
+
+	// the existing indexes are looked up rather than created with IF NOT EXISTS
+	suite.metadata.AddTable(users)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine, qb.IfNotExists))
+	_, err := suite.engine.Exec(qb.AlterTable(users).DropIndex("i_users_email"))
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine, qb.IfNotExists))
+	exists, err := suite.engine.Dialect().(*Dialect).IndexExists(context.Background(), suite.engine.DB(), "users", "i_users_email")
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), exists)
+
+	assert.Equal(suite.T(), "DROP TABLE IF EXISTS users;", users.Drop(NewDialect(), qb.IfExists))
+}
+
+func (suite *MysqlTestSuite) TestStringLiterals() {
+	users := qb.Table(
+		"users",
+		qb.Column("path", qb.Varchar()),
+		qb.Check(qb.Column("path", qb.Varchar()).NotEq(`C:\`)),
+	)
+
+	for _, dialect := range []qb.Dialect{NewDialect(), NewMariaDBDialect()} {
+		assert.Equal(suite.T(),
+			`CHECK (path != 'C:\\')`,
+			users.CheckConstraints[0].String(dialect))
+		assert.Equal(suite.T(),
+			`CHECK (path != 'it''s \\'' OR 1')`,
+			qb.Check(qb.Column("path", qb.Varchar()).NotEq(`it's \' OR 1`)).String(dialect))
+	}
+	assert.Equal(suite.T(),
+		`CREATE INDEX i_users_expr_f518c1d9 ON users((path = 'C:\\'))`,
+		qb.Index("users").Expr(users.C("path").Eq(`C:\`)).Create(NewDialect()))
+}
+
+func (suite *MysqlTestSuite) TestIndexes() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("deleted", qb.Boolean()),
+	)
+
+	assert.Equal(suite.T(),
+		"CREATE UNIQUE INDEX i_users_email ON users(email DESC) USING BTREE",
+		qb.UniqueIndex("users", "email").Desc("email").Using("btree").Create(NewDialect()))
+	assert.Equal(suite.T(),
+		"CREATE INDEX i_users_expr_9283c2fe ON users((LOWER(email)))",
+		qb.Index("users").Expr(qb.Aggregate("LOWER", users.C("email"))).Create(NewDialect()))
+
+	assert.Equal(suite.T(), "DROP INDEX i_users_email ON users;", qb.Index("users", "email").Drop(NewDialect()))
+	err := qb.DropIndex("users", "i_users_email", qb.IfExists).Build(NewDialect()).Err()
+	assert.Equal(suite.T(), qb.NotSupportedError(NewDialect(), qb.FeatureIndexIfExists), err)
+	assert.Equal(suite.T(),
+		"DROP INDEX IF EXISTS i_users_email ON users;",
+		qb.Index("users", "email").Drop(NewMariaDBDialect(), qb.IfExists))
+
+	err = qb.Index("users", "email").Where(users.C("deleted").Eq(false)).Build(NewDialect()).Err()
+	assert.Equal(suite.T(), qb.NotSupportedError(NewDialect(), qb.FeaturePartialIndex), err)
+	err = qb.Index("users").Expr(qb.Aggregate("LOWER", users.C("email"))).Build(NewMariaDBDialect()).Err()
+	assert.Equal(suite.T(), qb.NotSupportedError(NewMariaDBDialect(), qb.FeatureExpressionIndex), err)
 }
 
 func TestMysqlTestSuite(t *testing.T) {
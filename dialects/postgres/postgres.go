@@ -73,6 +73,16 @@ func (d *Dialect) AutoIncrement(column *qb.ColumnElem) string {
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *Dialect) SupportsUnsigned() bool { return false }
 
+// MaxBindParams returns the maximum number of bind parameters of a statement
+func (d *Dialect) MaxBindParams() int { return 65535 }
+
+// MaxPacketSize returns the maximum size in bytes of a statement and its bind
+// parameters, 0 meaning no limit
+func (d *Dialect) MaxPacketSize() int { return 0 }
+
+// Features returns the set of features supported by the dialect
+func (d *Dialect) Features() qb.Feature { return qb.AllFeatures }
+
 // Driver returns the current driver of dialect
 func (d *Dialect) Driver() string {
 	return "postgres"
@@ -156,50 +166,46 @@ func (PostgresCompiler) VisitBind(context qb.Context, bind qb.BindClause) string
 	return fmt.Sprintf("$%d", len(context.Binds()))
 }
 
-// VisitUpsert generates INSERT INTO ... VALUES ... ON CONFLICT(...) DO UPDATE SET ...
-func (PostgresCompiler) VisitUpsert(context qb.Context, upsert qb.UpsertStmt) string {
+// VisitIn compiles the IN clauses made with AsArray() as <left> = ANY($1),
+// and the NOT IN clauses as <left> != ALL($1), the values being bound as a
+// single array
+func (c PostgresCompiler) VisitIn(context qb.Context, in qb.InClause) string {
+	if in.Array == nil {
+		return c.SQLCompiler.VisitIn(context, in)
+	}
+	op := "= ANY"
+	if in.Op == "NOT IN" {
+		op = "!= ALL"
+	}
+	return fmt.Sprintf(
+		"%s %s(%s)",
+		in.Left.Accept(context),
+		op,
+		qb.Bind(pq.Array(in.Array)).Accept(context),
+	)
+}
+
+// VisitUpsert generates INSERT INTO ... VALUES ... ON CONFLICT ... DO UPDATE SET ...
+func (c PostgresCompiler) VisitUpsert(context qb.Context, upsert qb.UpsertStmt) string {
+	context.SetDefaultTableName(upsert.Table.Name)
+	defer func() { context.SetDefaultTableName("") }()
+
 	var (
 		colNames []string
 		values   []string
 	)
-	for k, v := range upsert.ValuesMap {
-		colNames = append(colNames, context.Compiler().VisitLabel(context, k))
-		context.AddBinds(v)
-		values = append(values, fmt.Sprintf("$%d", len(context.Binds())))
-	}
-
-	var updates []string
-	for k, v := range upsert.ValuesMap {
-		context.AddBinds(v)
-		updates = append(updates, fmt.Sprintf(
-			"%s = %s",
-			context.Dialect().Escape(k),
-			fmt.Sprintf("$%d", len(context.Binds())),
-		))
-	}
-
-	var uniqueCols []string
-	for _, c := range upsert.Table.PrimaryCols() {
-		uniqueCols = append(uniqueCols, context.Compiler().VisitLabel(context, c.Name))
+	for _, k := range upsert.ColumnNames() {
+		colNames = append(colNames, upsert.Table.C(k).Accept(context))
+		values = append(values, qb.GetClauseFrom(upsert.ValuesMap[k]).Accept(context))
 	}
 
 	sql := fmt.Sprintf(
-		"INSERT INTO %s(%s)\nVALUES(%s)\nON CONFLICT (%s) DO UPDATE SET %s",
-		context.Compiler().VisitLabel(context, upsert.Table.Name),
+		"INSERT INTO %s(%s)\nVALUES(%s)\n%s",
+		upsert.Table.Accept(context),
 		strings.Join(colNames, ", "),
 		strings.Join(values, ", "),
-		strings.Join(uniqueCols, ", "),
-		strings.Join(updates, ", "))
+		c.CompileOnConflict(context, upsert))
 
-	var returning []string
-	for _, r := range upsert.ReturningCols {
-		returning = append(returning, context.Compiler().VisitLabel(context, r.Name))
-	}
-	if len(returning) > 0 {
-		sql += fmt.Sprintf(
-			"RETURNING %s",
-			strings.Join(returning, ", "),
-		)
-	}
+	sql += c.CompileReturning(context, upsert.ReturningCols)
 	return sql
 }
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -50,6 +51,7 @@ func (suite *PostgresTestSuite) TestDialectSimple() {
 	assert.Equal(suite.T(), "test", dialect.Escape("test"))
 	assert.Equal(suite.T(), false, dialect.Escaping())
 	assert.Equal(suite.T(), "postgres", dialect.Driver())
+	assert.Equal(suite.T(), true, dialect.Features().Has(qb.FeatureReturning))
 }
 
 func (suite *PostgresTestSuite) TestDialectEscaping() {
@@ -284,6 +286,42 @@ func (suite *PostgresTestSuite) TestPostgres() {
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), actor.Bio, sql.NullString{Valid: false})
 
+	// upsert actor, the existing columns being qualified
+	upsert := qb.Upsert(actorsTable).
+		Values(map[string]interface{}{
+			"id":        "0f4c6d5e-8cf3-4a4f-9a8e-1b9b3c0e7f11",
+			"email":     "jack@nicholson.com",
+			"full_name": "Jack Nicholson",
+			"oscars":    3,
+		}).
+		OnConflict(actorsTable.C("email")).
+		DoUpdate(qb.Value("oscars", actorsTable.C("oscars").Add(qb.Excluded(actorsTable.C("oscars"))))).
+		DoUpdateWhere(actorsTable.C("oscars").Lt(10))
+	_, err = suite.engine.Exec(upsert)
+	assert.Nil(suite.T(), err)
+
+	// the default update set leaves the primary key untouched
+	_, err = suite.engine.Exec(qb.Upsert(actorsTable).
+		Values(map[string]interface{}{
+			"id":        "0f4c6d5e-8cf3-4a4f-9a8e-1b9b3c0e7f11",
+			"email":     "jack@nicholson.com",
+			"full_name": "John Joseph Nicholson",
+		}).
+		OnConflict(actorsTable.C("email")))
+	assert.Nil(suite.T(), err)
+
+	err = suite.engine.Get(sel, &actor)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "b6f8bfe3-a830-441a-a097-1777e6bfae95", actor.ID)
+	assert.Equal(suite.T(), "John Joseph Nicholson", actor.FullName)
+
+	var oscars int
+	err = suite.engine.QueryRow(qb.Select(actorsTable.C("oscars")).
+		From(actorsTable).
+		Where(actorsTable.C("email").Eq("jack@nicholson.com"))).Scan(&oscars)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, oscars)
+
 	// delete session
 	del := qb.Delete(sessionsTable).Where(
 		sessionsTable.C("auth_token").Eq("99e591f8-1025-41ef-a833-6904a0f89a38"),
@@ -344,7 +382,8 @@ func (suite *PostgresTestSuite) TestUpsert() {
 	assert.Contains(suite.T(), sql, "ON CONFLICT", "DO UPDATE SET")
 	assert.Contains(suite.T(), binds, "9883cf81-3b56-4151-ae4e-3903c5bc436d")
 	assert.Contains(suite.T(), binds, "al@pacino.com")
-	assert.Equal(suite.T(), 6, len(binds))
+	assert.Equal(suite.T(), 3, len(binds))
+	assert.Equal(suite.T(), "INSERT INTO users(id, email, created_at)\nVALUES($1, $2, $3)\nON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, created_at = EXCLUDED.created_at", sql)
 
 	ups = qb.Upsert(users).
 		Values(map[string]interface{}{
@@ -362,10 +401,213 @@ func (suite *PostgresTestSuite) TestUpsert() {
 	assert.Contains(suite.T(), sql, "ON CONFLICT")
 	assert.Contains(suite.T(), sql, "DO UPDATE SET")
 	assert.Contains(suite.T(), sql, "VALUES($1, $2)")
-	assert.Contains(suite.T(), sql, "RETURNING id, email")
+	assert.Contains(suite.T(), sql, "\nRETURNING id, email")
 	assert.Contains(suite.T(), binds, "9883cf81-3b56-4151-ae4e-3903c5bc436d")
 	assert.Contains(suite.T(), binds, "al@pacino.com")
-	assert.Equal(suite.T(), 4, len(binds))
+	assert.Equal(suite.T(), 2, len(binds))
+}
+
+func (suite *PostgresTestSuite) TestInsertFromSelect() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+	)
+	archive := qb.Table(
+		"archived_users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("reason", qb.Varchar()),
+	)
+
+	sel := qb.Select(users.C("id"), users.C("email"), qb.Bind("inactive")).
+		From(users).
+		Where(users.C("id").Gt(10))
+	ins := qb.Insert(archive).
+		FromSelect([]qb.ColumnElem{archive.C("id"), archive.C("email"), archive.C("reason")}, sel).
+		Returning(archive.C("id"))
+
+	sql := ins.Accept(suite.ctx)
+	assert.Equal(suite.T(), "INSERT INTO archived_users(id, email, reason)\nSELECT id, email, $1\nFROM users\nWHERE id > $2\nRETURNING id", sql)
+	assert.Equal(suite.T(), []interface{}{"inactive", 10}, suite.ctx.Binds())
+}
+
+func (suite *PostgresTestSuite) TestMultiTable() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+	)
+	sessions := qb.Table(
+		"sessions",
+		qb.Column("id", qb.Int()),
+		qb.Column("user_id", qb.Int()),
+	)
+
+	sql := qb.Update(users).
+		Values(map[string]interface{}{"email": "al@pacino.com"}).
+		From(sessions).
+		Where(qb.And(users.C("id").Eq(sessions.C("user_id")), sessions.C("id").Eq(5))).
+		Accept(suite.ctx)
+	assert.Equal(suite.T(), "UPDATE users\nSET email = $1\nFROM sessions\nWHERE (users.id = sessions.user_id AND sessions.id = $2)", sql)
+
+	ctx := qb.NewCompilerContext(NewDialect())
+	sql = qb.Delete(sessions).
+		Using(users).
+		Where(sessions.C("user_id").Eq(users.C("id"))).
+		Returning(sessions.C("id")).
+		Accept(ctx)
+	assert.Equal(suite.T(), "DELETE FROM sessions\nUSING users\nWHERE sessions.user_id = users.id\nRETURNING id", sql)
+}
+
+func (suite *PostgresTestSuite) TestUpsertExpressions() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("updated_at", qb.Timestamp()),
+		qb.PrimaryKey("id"),
+	)
+
+	sql := qb.Upsert(users).
+		OrderedValues(qb.Value("id", 5), qb.Value("updated_at", qb.SQLText("CURRENT_TIMESTAMP"))).
+		Accept(suite.ctx)
+	assert.Equal(suite.T(), "INSERT INTO users(id, updated_at)\nVALUES($1, CURRENT_TIMESTAMP)\nON CONFLICT (id) DO UPDATE SET updated_at = EXCLUDED.updated_at", sql)
+	assert.Equal(suite.T(), []interface{}{5}, suite.ctx.Binds())
+}
+
+func (suite *PostgresTestSuite) TestInArray() {
+	id := qb.Column("id", qb.Int())
+	ids := []int64{1, 2, 3}
+
+	ctx := qb.NewCompilerContext(NewDialect())
+	assert.Equal(suite.T(), "id IN ($1, $2, $3)", qb.In(id, ids).Accept(ctx))
+	assert.Equal(suite.T(), []interface{}{int64(1), int64(2), int64(3)}, ctx.Binds())
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	assert.Equal(suite.T(), "id = ANY($1)", id.In(ids).AsArray().Accept(ctx))
+	assert.Equal(suite.T(), []interface{}{pq.Array(ids)}, ctx.Binds())
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	assert.Equal(suite.T(), "id != ALL($1)", qb.NotIn(id, []int64{}).AsArray().Accept(ctx))
+	assert.Equal(suite.T(), []interface{}{pq.Array([]int64{})}, ctx.Binds())
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	assert.Equal(suite.T(), "id IN ($1, $2)", qb.In(id, 1, 2).AsArray().Accept(ctx))
+}
+
+func (suite *PostgresTestSuite) TestAlterTable() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+	)
+
+	sql := qb.AlterTable(users).
+		AddColumn(qb.Column("uid", qb.UUID()).NotNull()).
+		AlterColumnType("email", qb.Text()).
+		SetNotNull("email").
+		RenameTo("members").
+		Build(NewDialect()).SQL()
+	assert.Equal(suite.T(), strings.Join([]string{
+		"ALTER TABLE users ADD COLUMN uid UUID NOT NULL, ALTER COLUMN email TYPE TEXT",
+		"ALTER TABLE users ALTER COLUMN email SET NOT NULL",
+		"ALTER TABLE users RENAME TO members;",
+	}, ";\n"), sql)
+}
+
+func (suite *PostgresTestSuite) TestDDLOptions() {
+	users := qb.Table("users", qb.Column("id", qb.Int()).PrimaryKey())
+	assert.Equal(suite.T(), "DROP TABLE IF EXISTS users CASCADE;", users.Drop(NewDialect(), qb.IfExists, qb.Cascade))
+}
+
+func (suite *PostgresTestSuite) TestIndexes() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("tags", qb.Text()),
+		qb.Column("deleted", qb.Boolean()),
+	)
+	dialect := NewDialect()
+
+	assert.Equal(suite.T(),
+		"CREATE UNIQUE INDEX CONCURRENTLY i_users_expr_9283c2fe ON users((LOWER(email))) WHERE deleted = FALSE",
+		qb.UniqueIndex("users").
+			Expr(qb.Aggregate("LOWER", users.C("email"))).
+			Where(users.C("deleted").Eq(false)).
+			Create(dialect, qb.Concurrently))
+	assert.Equal(suite.T(),
+		"CREATE INDEX i_users_tags ON users USING gin(tags)",
+		qb.Index("users", "tags").Using("gin").Create(dialect))
+	assert.Equal(suite.T(),
+		"DROP INDEX CONCURRENTLY IF EXISTS i_users_tags;",
+		qb.Index("users", "tags").Drop(dialect, qb.IfExists, qb.Concurrently))
+}
+
+func (suite *PostgresTestSuite) TestUpsertUnknownColumn() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("logins", qb.Int()),
+	)
+
+	err := qb.Upsert(users).
+		Values(map[string]interface{}{"id": 1, "login": 1}).
+		Build(NewDialect()).Err()
+	assert.Equal(suite.T(), qb.CompileError("No such column 'login' in table users"), err)
+
+	err = qb.Upsert(users).
+		Values(map[string]interface{}{"id": 1, "logins": 1}).
+		DoUpdate(qb.Value("login", 2)).
+		Build(NewDialect()).Err()
+	assert.Equal(suite.T(), qb.CompileError("No such column 'login' in table users"), err)
+}
+
+func (suite *PostgresTestSuite) TestUpsertOnConflict() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("logins", qb.Int()),
+		qb.Column("deleted", qb.Boolean()),
+		qb.PrimaryKey("id"),
+	)
+	values := []qb.ColumnValue{qb.Value("id", 1), qb.Value("email", "al@pacino.com"), qb.Value("logins", 1)}
+
+	ctx := qb.NewCompilerContext(NewDialect())
+	sql := qb.Upsert(users).
+		OrderedValues(values...).
+		OnConflict(users.C("email")).
+		OnConflictWhere(users.C("deleted").Eq(false)).
+		DoUpdate(qb.Value("logins", users.C("logins").Add(qb.Excluded(users.C("logins"))))).
+		DoUpdateWhere(users.C("logins").Lt(100)).
+		Accept(ctx)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"INSERT INTO users(id, email, logins)",
+		"VALUES($1, $2, $3)",
+		"ON CONFLICT (email) WHERE deleted = $4 DO UPDATE SET logins = users.logins + EXCLUDED.logins WHERE users.logins < $5",
+	}, "\n"), sql)
+	assert.Equal(suite.T(), []interface{}{1, "al@pacino.com", 1, false, 100}, ctx.Binds())
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Upsert(users).
+		OrderedValues(values...).
+		OnConstraint("users_email_key").
+		DoUpdateColumns(users.C("logins")).
+		Accept(ctx)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"INSERT INTO users(id, email, logins)",
+		"VALUES($1, $2, $3)",
+		"ON CONFLICT ON CONSTRAINT users_email_key DO UPDATE SET logins = EXCLUDED.logins",
+	}, "\n"), sql)
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Upsert(users).OrderedValues(values...).DoNothing().Accept(ctx)
+	assert.Equal(suite.T(), "INSERT INTO users(id, email, logins)\nVALUES($1, $2, $3)\nON CONFLICT DO NOTHING", sql)
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	sql = qb.Upsert(users).OrderedValues(qb.Value("id", 1)).Accept(ctx)
+	assert.Equal(suite.T(), "INSERT INTO users(id)\nVALUES($1)\nON CONFLICT (id) DO NOTHING", sql)
 }
 
 func TestPostgresTestSuite(t *testing.T) {
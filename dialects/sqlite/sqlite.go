@@ -1,6 +1,8 @@
 package sqlite
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -28,6 +30,10 @@ func (d *Dialect) CompileType(t qb.TypeElem) string {
 	if t.Name == "UUID" {
 		return "VARCHAR(36)"
 	}
+	// booleans are stored as integers before sqlite 3.23.0
+	if t.Name == "BOOLEAN" && !d.Features().Has(qb.FeatureBoolean) {
+		return "INTEGER"
+	}
 	return qb.DefaultCompileType(t, d.SupportsUnsigned())
 }
 
@@ -55,9 +61,12 @@ func (d *Dialect) Escaping() bool {
 }
 
 // AutoIncrement generates auto increment sql of current dialect
+// Sqlite only supports auto-increment primary keys, the table definition
+// fails to build for other columns, and sqlite rejects their AUTOINCREMENT
+// keyword if the definition is executed anyway
 func (d *Dialect) AutoIncrement(column *qb.ColumnElem) string {
 	if !column.Options.InlinePrimaryKey {
-		panic("Sqlite does not support non-primarykey autoincrement columns")
+		return d.CompileType(column.Type) + " AUTOINCREMENT"
 	}
 	return "INTEGER PRIMARY KEY"
 }
@@ -65,6 +74,36 @@ func (d *Dialect) AutoIncrement(column *qb.ColumnElem) string {
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *Dialect) SupportsUnsigned() bool { return false }
 
+// MaxBindParams returns the maximum number of bind parameters of a statement
+// SQLite raised its default limit from 999 to 32766 in 3.32.0. The lowest value
+// is used as the limit is a compile time option of the library
+func (d *Dialect) MaxBindParams() int { return 999 }
+
+// MaxPacketSize returns the maximum size in bytes of a statement and its bind
+// parameters, 0 meaning no limit
+func (d *Dialect) MaxPacketSize() int { return 0 }
+
+// Features returns the set of features supported by the dialect, which
+// depends on the version of the sqlite library
+func (d *Dialect) Features() qb.Feature {
+	_, version, _ := sqlite3.Version()
+	features := qb.FeatureWindow | qb.FeatureCTE | qb.FeaturePartialIndex |
+		qb.FeatureExpressionIndex | qb.FeatureIntersect | qb.FeatureIndexIfExists
+	if version >= 3023000 {
+		features |= qb.FeatureBoolean
+	}
+	if version >= 3033000 {
+		features |= qb.FeatureUpdateFrom
+	}
+	if version >= 3035000 {
+		features |= qb.FeatureReturning
+	}
+	if version >= 3039000 {
+		features |= qb.FeatureRightJoin | qb.FeatureFullJoin
+	}
+	return features
+}
+
 // Driver returns the current driver of dialect
 func (d *Dialect) Driver() string {
 	return "sqlite3"
@@ -116,29 +155,286 @@ func (d *Dialect) WrapError(err error) qb.Error {
 	return qbErr
 }
 
+// PrepareScript disables the foreign keys while a table is rebuilt to be
+// altered, dropping the old table would otherwise delete or reject the rows
+// referencing it. The foreign keys are checked before the rebuild is
+// committed and enabled again once it is over.
+// Sqlite cannot disable the foreign keys in a transaction, a rebuild is then
+// refused in a transaction while they are enabled
+func (d *Dialect) PrepareScript(ctx context.Context, conn qb.Conn, inTx bool, builder qb.Builder) (func(qb.Conn) error, func() error, error) {
+	check := func(qb.Conn) error { return nil }
+	restore := func() error { return nil }
+	alter, ok := builder.(qb.AlterTableStmt)
+	if !ok || !rebuilds(alter) {
+		return check, restore, nil
+	}
+	enabled, err := foreignKeys(ctx, conn)
+	if err != nil || !enabled {
+		return check, restore, err
+	}
+	if inTx {
+		return nil, nil, qb.Error{
+			Code: qb.ErrUnsupported,
+			Orig: fmt.Errorf("table %s cannot be rebuilt in a transaction while the foreign keys are enabled", alter.Table.Name),
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return nil, nil, err
+	}
+
+	check = func(tx qb.Conn) error {
+		rows, err := tx.QueryContext(ctx, "PRAGMA foreign_key_check")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		if !rows.Next() {
+			return rows.Err()
+		}
+		var table, parent string
+		var rowid, fkid interface{}
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return err
+		}
+		return qb.Error{
+			Code: qb.ErrIntegrity,
+			Orig: fmt.Errorf("FOREIGN KEY constraint failed: %s rows reference missing %s rows", table, parent),
+		}
+	}
+	restore = func() error {
+		_, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+		return err
+	}
+	return check, restore, nil
+}
+
+// foreignKeys returns true if the foreign keys are enabled on the connection
+func foreignKeys(ctx context.Context, conn qb.Conn) (bool, error) {
+	rows, err := conn.QueryContext(ctx, "PRAGMA foreign_keys")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	enabled := false
+	if rows.Next() {
+		err = rows.Scan(&enabled)
+	}
+	if err != nil {
+		return false, err
+	}
+	return enabled, rows.Err()
+}
+
 // SqliteCompiler is a SQLCompiler specialised for Sqlite
 type SqliteCompiler struct {
 	qb.SQLCompiler
 }
 
-// VisitUpsert generates the following sql: REPLACE INTO ... VALUES ...
-func (SqliteCompiler) VisitUpsert(context qb.Context, upsert qb.UpsertStmt) string {
+// VisitCompound compiles a compound select (UNION, INTERSECT...)
+// SQLite does not allow parentheses around the select statements of a
+// compound select, the ones having their own ORDER BY, LIMIT or OFFSET
+// clause are then selected from as subqueries.
+// INTERSECT ALL and EXCEPT ALL are not supported
+func (c SqliteCompiler) VisitCompound(context qb.Context, compound qb.CompoundClause) string {
+	selects := []qb.SelectStmt{}
+	for i, sel := range compound.Selects {
+		if sel.OrderByClause != nil || sel.LimitValue != nil || sel.OffsetValue != nil {
+			sel = qb.Select(qb.SQLText("*")).From(qb.Alias(fmt.Sprintf("qb_compound_%d", i+1), sel))
+		}
+		selects = append(selects, sel)
+	}
+	compound.Selects = selects
+	return c.SQLCompiler.VisitCompound(context, compound)
+}
+
+// VisitDelete compiles a multi-table delete, which sqlite does not support,
+// as DELETE FROM ... WHERE EXISTS(SELECT 1 FROM <using tables> WHERE ...)
+func (c SqliteCompiler) VisitDelete(context qb.Context, delete qb.DeleteStmt) string {
+	if len(delete.UsingTables) == 0 {
+		return c.SQLCompiler.VisitDelete(context, delete)
+	}
+
+	sel := qb.Select(qb.SQLText("1")).From(delete.UsingTables[0])
+	for _, t := range delete.UsingTables[1:] {
+		sel = sel.CrossJoin(t)
+	}
+	sel.WhereClause = delete.WhereClause
+
+	rewritten := qb.Delete(delete.Table).Where(qb.Exists(sel))
+	rewritten.ReturningCols = delete.ReturningCols
+	return c.SQLCompiler.VisitDelete(context, rewritten)
+}
+
+// VisitUpdate compiles a multi-table update, which sqlite supports as
+// UPDATE ... FROM since 3.33.0 only. Before, the values referring to the
+// tables are selected from them by correlated subqueries, and the updated rows
+// are those matching WHERE EXISTS(SELECT 1 FROM <from tables> WHERE ...)
+func (c SqliteCompiler) VisitUpdate(context qb.Context, update qb.UpdateStmt) string {
+	if len(update.FromTables) == 0 || c.Dialect.Features().Has(qb.FeatureUpdateFrom) {
+		return c.SQLCompiler.VisitUpdate(context, update)
+	}
+
+	from := func(sel qb.SelectStmt) qb.SelectStmt {
+		sel = sel.From(update.FromTables[0])
+		for _, t := range update.FromTables[1:] {
+			sel = sel.CrossJoin(t)
+		}
+		sel.WhereClause = update.WhereClause
+		return sel
+	}
+
+	rewritten := qb.Update(update.Table).Where(qb.Exists(from(qb.Select(qb.SQLText("1")))))
+	rewritten.ValuesOrder = update.ValuesOrder
+	for name, value := range update.ValuesMap {
+		if clause, ok := value.(qb.Clause); ok {
+			if _, ok := clause.(qb.BindClause); !ok {
+				value = from(qb.Select(clause))
+			}
+		}
+		rewritten.ValuesMap[name] = value
+	}
+	rewritten.ReturningCols = update.ReturningCols
+	return c.SQLCompiler.VisitUpdate(context, rewritten)
+}
+
+// VisitUpsert generates INSERT INTO ... VALUES ... ON CONFLICT ... DO UPDATE SET ...
+// It requires sqlite 3.24.0 or later
+func (c SqliteCompiler) VisitUpsert(context qb.Context, upsert qb.UpsertStmt) string {
+	if upsert.ConflictConstraint != "" {
+		context.AddError(qb.Error{
+			Code: qb.ErrUnsupported,
+			Orig: errors.New("Sqlite does not support ON CONFLICT ON CONSTRAINT, use OnConflict() instead"),
+		})
+	}
+	context.SetDefaultTableName(upsert.Table.Name)
+	defer func() { context.SetDefaultTableName("") }()
+
 	var (
 		colNames []string
 		values   []string
 	)
-	for k, v := range upsert.ValuesMap {
-		colNames = append(colNames, context.Compiler().VisitLabel(context, k))
-		context.AddBinds(v)
-		values = append(values, "?")
+	for _, k := range upsert.ColumnNames() {
+		colNames = append(colNames, upsert.Table.C(k).Accept(context))
+		values = append(values, qb.GetClauseFrom(upsert.ValuesMap[k]).Accept(context))
 	}
 
 	sql := fmt.Sprintf(
-		"REPLACE INTO %s(%s)\nVALUES(%s)",
-		context.Compiler().VisitLabel(context, upsert.Table.Name),
+		"INSERT INTO %s(%s)\nVALUES(%s)\n%s",
+		upsert.Table.Accept(context),
 		strings.Join(colNames, ", "),
 		strings.Join(values, ", "),
+		c.CompileOnConflict(context, upsert),
 	)
+	sql += c.CompileReturning(context, upsert.ReturningCols)
 
 	return sql
 }
+
+// CompileAlterTable compiles an ALTER TABLE statement, each action being
+// compiled as a separate statement
+// Sqlite can only add, rename and (since 3.35.0) drop columns, and rename
+// tables. If any action cannot be done natively, the table is rebuilt: the
+// altered table is created under a temporary name, the rows are copied, the
+// old table is dropped, the new one renamed and its indexes created.
+// Engine.Exec and Tx.Exec execute the statements of a rebuild with the
+// foreign keys disabled, see PrepareScript
+func (c SqliteCompiler) CompileAlterTable(context qb.Context, alter qb.AlterTableStmt) []string {
+	if rebuilds(alter) {
+		return c.rebuildTable(context, alter)
+	}
+	statements := []string{}
+	table := alter.Table
+	for _, action := range alter.Actions {
+		statements = append(statements, c.CompileAlterAction(context, table, action))
+		table = action.Apply(table)
+	}
+	return statements
+}
+
+// rebuilds returns true if the table is rebuilt to be altered
+func rebuilds(alter qb.AlterTableStmt) bool {
+	for _, action := range alter.Actions {
+		if needsRebuild(action) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsRebuild returns true if the action cannot be done with sqlite
+// ALTER TABLE statement
+func needsRebuild(action qb.AlterAction) bool {
+	_, version, _ := sqlite3.Version()
+	switch action.Op {
+	case qb.AlterAddColumn:
+		col := action.Column
+		if col.Options.PrimaryKey || col.Options.Unique {
+			return true
+		}
+		notNull, hasDefault := false, false
+		for _, constraint := range col.Constraints {
+			notNull = notNull || constraint.Name == "NOT NULL"
+			hasDefault = hasDefault || strings.HasPrefix(constraint.Name, "DEFAULT ")
+		}
+		return notNull && !hasDefault
+	case qb.AlterDropColumn:
+		return version < 3035000
+	case qb.AlterRenameColumn:
+		return version < 3025000
+	case qb.AlterRenameTable, qb.AlterAddIndex, qb.AlterDropIndex:
+		return false
+	}
+	return true
+}
+
+// rebuildTable compiles the statements that replace a table by its altered
+// definition, keeping the rows of the columns that are not dropped
+func (c SqliteCompiler) rebuildTable(context qb.Context, alter qb.AlterTableStmt) []string {
+	altered := alter.Altered()
+
+	// sources maps the columns of the altered table to the original ones
+	sources := map[string]string{}
+	for _, name := range alter.Table.ColumnNames() {
+		sources[name] = name
+	}
+	for _, action := range alter.Actions {
+		switch action.Op {
+		case qb.AlterRenameColumn:
+			if source, ok := sources[action.Name]; ok {
+				sources[action.NewName] = source
+			}
+			delete(sources, action.Name)
+		case qb.AlterDropColumn, qb.AlterAddColumn:
+			delete(sources, action.Name)
+		}
+	}
+	var cols, sourceCols []string
+	for _, name := range altered.ColumnNames() {
+		if source, ok := sources[name]; ok {
+			cols = append(cols, c.Dialect.Escape(name))
+			sourceCols = append(sourceCols, c.Dialect.Escape(source))
+		}
+	}
+
+	tmp := altered
+	tmp.Name = "qb_tmp_" + altered.Name
+	tmp.Indices = nil
+	statements := []string{strings.TrimSuffix(tmp.Create(c.Dialect), ";")}
+	if len(cols) > 0 {
+		statements = append(statements, fmt.Sprintf(
+			"INSERT INTO %s(%s) SELECT %s FROM %s",
+			c.Dialect.Escape(tmp.Name),
+			strings.Join(cols, ", "),
+			strings.Join(sourceCols, ", "),
+			c.Dialect.Escape(alter.Table.Name),
+		))
+	}
+	statements = append(statements,
+		"DROP TABLE "+c.Dialect.Escape(alter.Table.Name),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", c.Dialect.Escape(tmp.Name), c.Dialect.Escape(altered.Name)),
+	)
+	for _, index := range altered.Indices {
+		statements = append(statements, index.Accept(context))
+	}
+	return statements
+}
@@ -3,6 +3,8 @@ package sqlite
 import (
 	"database/sql"
 	"errors"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +33,11 @@ func (suite *SqliteTestSuite) SetupTest() {
 
 func (suite *SqliteTestSuite) TestUUID() {
 	assert.Equal(suite.T(), "VARCHAR(36)", suite.engine.Dialect().CompileType(qb.UUID()))
+	if suite.engine.Dialect().Features().Has(qb.FeatureBoolean) {
+		assert.Equal(suite.T(), "BOOLEAN", suite.engine.Dialect().CompileType(qb.Boolean()))
+	} else {
+		assert.Equal(suite.T(), "INTEGER", suite.engine.Dialect().CompileType(qb.Boolean()))
+	}
 }
 
 func (suite *SqliteTestSuite) TestDialect() {
@@ -213,25 +220,848 @@ func (suite *SqliteTestSuite) TestUpsert() {
 	ctx := qb.NewCompilerContext(NewDialect())
 	sql := ups.Accept(ctx)
 	binds := ctx.Binds()
-	assert.Contains(suite.T(), sql, `REPLACE INTO users`)
+	assert.Contains(suite.T(), sql, `INSERT INTO users`)
 	assert.Contains(suite.T(), sql, "id", "email", "created_at")
 	assert.Contains(suite.T(), sql, "VALUES(?, ?, ?)")
 	assert.Contains(suite.T(), binds, "9883cf81-3b56-4151-ae4e-3903c5bc436d")
 	assert.Contains(suite.T(), binds, "al@pacino.com")
 	assert.Contains(suite.T(), binds, now)
 	assert.Equal(suite.T(), 3, len(binds))
+	assert.Equal(suite.T(), "INSERT INTO users(id, email, created_at)\nVALUES(?, ?, ?)\nON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, created_at = EXCLUDED.created_at", sql)
+	assert.Equal(suite.T(), []interface{}{"9883cf81-3b56-4151-ae4e-3903c5bc436d", "al@pacino.com", now}, binds)
+
+	err := qb.Upsert(users).Values(map[string]interface{}{"id": "1"}).OnConstraint("users_pkey").Build(NewDialect()).Err()
+	assert.Equal(suite.T(), qb.ErrUnsupported, err.(qb.Error).Code)
+}
+
+func (suite *SqliteTestSuite) TestUpsertOnConflict() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()).Unique(),
+		qb.Column("logins", qb.Int()).NotNull(),
+	)
+	sessions := qb.Table(
+		"sessions",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("user_id", qb.Int()).NotNull(),
+		qb.ForeignKey("user_id").References("users", "id"),
+	)
+	suite.metadata.AddTable(users)
+	suite.metadata.AddTable(sessions)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(qb.Insert(users).Values(map[string]interface{}{"id": 1, "email": "al@pacino.com", "logins": 1}))
+	assert.Nil(suite.T(), err)
+	_, err = suite.engine.Exec(qb.Insert(sessions).Values(map[string]interface{}{"id": 1, "user_id": 1}))
+	assert.Nil(suite.T(), err)
+
+	login := func() qb.UpsertStmt {
+		return qb.Upsert(users).
+			Values(map[string]interface{}{"id": 2, "email": "al@pacino.com", "logins": 1}).
+			OnConflict(users.C("email")).
+			DoUpdate(qb.Value("logins", users.C("logins").Add(qb.Excluded(users.C("logins")))))
+	}
+
+	ctx := qb.NewCompilerContext(NewDialect())
+	assert.Equal(suite.T(), strings.Join([]string{
+		"INSERT INTO users(id, email, logins)",
+		"VALUES(?, ?, ?)",
+		"ON CONFLICT (email) DO UPDATE SET logins = users.logins + EXCLUDED.logins",
+	}, "\n"), login().Accept(ctx))
+
+	_, err = suite.engine.Exec(login())
+	assert.Nil(suite.T(), err)
+	_, err = suite.engine.Exec(login().DoUpdateWhere(users.C("logins").Lt(2)))
+	assert.Nil(suite.T(), err)
+	_, err = suite.engine.Exec(login().DoNothing())
+	assert.Nil(suite.T(), err)
+
+	var logins int
+	err = suite.engine.QueryRow(qb.Select(users.C("logins")).From(users).Where(users.C("id").Eq(1))).Scan(&logins)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, logins)
+
+	// the row was updated in place, not replaced
+	var sessionCount int
+	err = suite.engine.QueryRow(qb.Select(qb.Count(sessions.C("id"))).From(sessions)).Scan(&sessionCount)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, sessionCount)
+
+	// the default update set leaves the primary key untouched
+	_, err = suite.engine.Exec(qb.Upsert(users).
+		Values(map[string]interface{}{"id": 2, "email": "al@pacino.com", "logins": 5}).
+		OnConflict(users.C("email")))
+	assert.Nil(suite.T(), err)
+	var ids []int
+	err = suite.engine.Select(qb.Select(users.C("id")).From(users).Where(users.C("logins").Eq(5)), &ids)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int{1}, ids)
 }
 
 func (suite *SqliteTestSuite) TestSqliteAutoIncrement() {
 	col := qb.Column("test", qb.Int()).AutoIncrement()
-	assert.Panics(suite.T(), func() {
-		col.String(suite.engine.Dialect())
-	})
+	assert.Equal(suite.T(), "test INT AUTOINCREMENT", col.String(suite.engine.Dialect()))
+
+	table := qb.Table("test", qb.Column("id", qb.Int()).PrimaryKey(), col)
+	err := table.Build(suite.engine.Dialect()).Err()
+	assert.Equal(suite.T(), qb.ErrUnsupported, err.(qb.Error).Code)
+	assert.Equal(suite.T(), "Unsupported feature: AUTOINCREMENT on non primary key columns is not supported by the sqlite3 dialect", err.Error())
+
+	// the column is not silently created without auto-increment
+	_, err = suite.engine.DB().Exec(table.Create(suite.engine.Dialect()))
+	assert.NotNil(suite.T(), err)
 
 	col.Options.InlinePrimaryKey = true
 	assert.Equal(suite.T(), "INTEGER PRIMARY KEY", suite.engine.Dialect().AutoIncrement(&col))
 }
 
+func (suite *SqliteTestSuite) TestWith() {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(suite.T(), err)
+	defer engine.Close()
+
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+		qb.PrimaryKey("id"),
+	)
+	_, err = engine.DB().Exec(users.Create(engine.Dialect()))
+	assert.Nil(suite.T(), err)
+
+	for i, email := range []string{"al@pacino.com", "jack@nicholson.com"} {
+		_, err = engine.Exec(users.Insert().Values(map[string]interface{}{
+			"id":    i + 1,
+			"email": email,
+		}))
+		assert.Nil(suite.T(), err)
+	}
+
+	first := qb.With("first", users.Select(users.C("id"), users.C("email")).Where(users.C("id").Eq(1)))
+	sel := qb.Select(first.C("email")).With(first).From(first)
+
+	var emails []string
+	assert.Nil(suite.T(), engine.Select(sel, &emails))
+	assert.Equal(suite.T(), []string{"al@pacino.com"}, emails)
+}
+
+func (suite *SqliteTestSuite) TestCompound() {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(suite.T(), err)
+	defer engine.Close()
+
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+	)
+	_, err = engine.DB().Exec(users.Create(engine.Dialect()))
+	assert.Nil(suite.T(), err)
+
+	for i, email := range []string{"al@pacino.com", "jack@nicholson.com", "robert@deniro.com"} {
+		_, err = engine.Exec(users.Insert().Values(map[string]interface{}{
+			"id":    i + 1,
+			"email": email,
+		}))
+		assert.Nil(suite.T(), err)
+	}
+
+	lower := users.Select(users.C("email")).Where(users.C("id").Lte(2))
+	upper := users.Select(users.C("email")).Where(users.C("id").Gte(2))
+
+	for _, tt := range []struct {
+		compound qb.CompoundClause
+		expected []string
+	}{
+		{qb.Union(lower, upper), []string{"al@pacino.com", "jack@nicholson.com", "robert@deniro.com"}},
+		{qb.UnionAll(lower, upper), []string{"al@pacino.com", "jack@nicholson.com", "jack@nicholson.com", "robert@deniro.com"}},
+		{qb.Intersect(lower, upper), []string{"jack@nicholson.com"}},
+		{qb.Except(lower, upper), []string{"al@pacino.com"}},
+		{qb.Union(lower.OrderBy(users.C("id")).Limit(1), upper.OrderBy(users.C("id")).Desc().Limit(1)),
+			[]string{"al@pacino.com", "robert@deniro.com"}},
+	} {
+		var emails []string
+		compound := tt.compound.OrderBy(tt.compound.C("email"))
+		assert.Nil(suite.T(), engine.Select(compound, &emails))
+		assert.Equal(suite.T(), tt.expected, emails)
+	}
+
+	err = qb.Compound("INTERSECT ALL", lower, upper).Build(engine.Dialect()).Err()
+	assert.Equal(suite.T(), qb.NotSupportedError(engine.Dialect(), qb.FeatureIntersectAll), err)
+}
+
+func (suite *SqliteTestSuite) TestWindow() {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(suite.T(), err)
+	defer engine.Close()
+
+	scores := qb.Table(
+		"scores",
+		qb.Column("id", qb.Int()),
+		qb.Column("player", qb.Varchar()),
+		qb.Column("points", qb.Int()),
+	)
+	_, err = engine.DB().Exec(scores.Create(engine.Dialect()))
+	assert.Nil(suite.T(), err)
+
+	for i, player := range []string{"al", "jack", "al", "jack", "al"} {
+		_, err = engine.Exec(scores.Insert().Values(map[string]interface{}{
+			"id":     i + 1,
+			"player": player,
+			"points": 10 * (i + 1),
+		}))
+		assert.Nil(suite.T(), err)
+	}
+
+	sel := qb.Select(
+		qb.RowNumber().Over(qb.WindowRef("w")),
+		qb.Sum(scores.C("points")).Over(qb.WindowRef("w")),
+	).
+		From(scores).
+		Where(scores.C("player").Eq("al")).
+		Window("w", qb.Window().
+			PartitionBy(scores.C("player")).
+			OrderBy(scores.C("id")).
+			Rows(qb.UnboundedPreceding, qb.CurrentRow)).
+		OrderBy(scores.C("id"))
+
+	rows, err := engine.Query(sel)
+	assert.Nil(suite.T(), err)
+	defer rows.Close()
+
+	var results [][2]int
+	for rows.Next() {
+		var result [2]int
+		assert.Nil(suite.T(), rows.Scan(&result[0], &result[1]))
+		results = append(results, result)
+	}
+	assert.Equal(suite.T(), [][2]int{{1, 10}, {2, 40}, {3, 90}}, results)
+}
+
+func (suite *SqliteTestSuite) TestInsertFromSelect() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+	)
+	archive := qb.Table(
+		"archived_users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+	)
+	suite.metadata.AddTable(users)
+	suite.metadata.AddTable(archive)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(qb.Insert(users).Rows(
+		map[string]interface{}{"id": 1, "email": "al@pacino.com"},
+		map[string]interface{}{"id": 2, "email": "robert@deniro.com"},
+		map[string]interface{}{"id": 3, "email": "jack@nicholson.com"},
+	))
+	assert.Nil(suite.T(), err)
+
+	res, err := suite.engine.Exec(qb.Insert(archive).FromSelect(
+		[]qb.ColumnElem{archive.C("id"), archive.C("email")},
+		qb.Select(users.C("id"), users.C("email")).From(users).Where(users.C("id").Gt(1)),
+	))
+	assert.Nil(suite.T(), err)
+	rowsAffected, err := res.RowsAffected()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), rowsAffected)
+
+	var emails []string
+	err = suite.engine.Select(qb.Select(archive.C("email")).From(archive).OrderBy(archive.C("id")), &emails)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"robert@deniro.com", "jack@nicholson.com"}, emails)
+}
+
+func (suite *SqliteTestSuite) TestReturning() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()),
+	)
+	suite.metadata.AddTable(users)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	type User struct {
+		ID    int64
+		Email string
+	}
+
+	var user User
+	err := suite.engine.Get(qb.Insert(users).
+		Values(map[string]interface{}{"email": "al@pacino.com"}).
+		Returning(users.C("id"), users.C("email")), &user)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), User{ID: 1, Email: "al@pacino.com"}, user)
+
+	var emails []string
+	err = suite.engine.Select(qb.Insert(users).Rows(
+		map[string]interface{}{"id": 5, "email": "robert@deniro.com"},
+		map[string]interface{}{"id": 6, "email": "jack@nicholson.com"},
+	).Returning(users.C("email")), &emails)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"robert@deniro.com", "jack@nicholson.com"}, emails)
+
+	var id int64
+	err = suite.engine.Transaction(func(tx *qb.Tx) error {
+		return tx.QueryRow(qb.Insert(users).
+			Values(map[string]interface{}{"email": "marlon@brando.com"}).
+			Returning(users.C("id"))).Scan(&id)
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(7), id)
+
+	if suite.engine.Dialect().Features().Has(qb.FeatureReturning) {
+		return
+	}
+
+	// the emulation runs on a dedicated connection outside of a transaction
+	insert := qb.Insert(users).
+		Values(map[string]interface{}{"email": "marlon@brando.com"}).
+		Returning(users.C("id"))
+	err = suite.engine.QueryRow(insert).Scan(&id)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(8), id)
+	rows, err := suite.engine.Query(insert)
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), rows.Next())
+	assert.Nil(suite.T(), rows.Scan(&id))
+	assert.False(suite.T(), rows.Next())
+	assert.Nil(suite.T(), rows.Close())
+	assert.Equal(suite.T(), int64(9), id)
+	var count int
+	err = suite.engine.QueryRow(qb.Select(qb.Count(users.C("id"))).From(users)).Scan(&count)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 6, count)
+
+	err = suite.engine.Select(qb.Insert(users).Rows(
+		map[string]interface{}{"email": "robert@deniro.com"},
+		map[string]interface{}{"email": "jack@nicholson.com"},
+	).Returning(users.C("id")), &emails)
+	assert.Equal(suite.T(), qb.ErrUnsupported, err.(qb.Error).Code)
+
+	err = suite.engine.Select(qb.Update(users).
+		Values(map[string]interface{}{"email": "al@pacino.com"}).
+		Returning(users.C("id")), &emails)
+	assert.Equal(suite.T(), qb.ErrUnsupported, err.(qb.Error).Code)
+	assert.Equal(suite.T(), "Unsupported feature: RETURNING is not supported by the sqlite3 dialect", err.Error())
+}
+
+func (suite *SqliteTestSuite) TestFeatures() {
+	users := qb.Table("users", qb.Column("id", qb.Int()).PrimaryKey())
+	sessions := qb.Table("sessions", qb.Column("user_id", qb.Int()))
+
+	stmt := qb.Select(users.C("id")).From(users).ForUpdate().Build(suite.engine.Dialect())
+	assert.Equal(suite.T(), qb.ErrUnsupported, stmt.Err().(qb.Error).Code)
+
+	_, err := suite.engine.Query(qb.Select(users.C("id")).From(users).ForUpdate())
+	assert.Equal(suite.T(), stmt.Err().Error(), err.Error())
+
+	stmt = qb.Select(users.C("id")).
+		From(users).
+		FullJoin(sessions, users.C("id").Eq(sessions.C("user_id"))).
+		Build(suite.engine.Dialect())
+	if suite.engine.Dialect().Features().Has(qb.FeatureFullJoin) {
+		assert.Nil(suite.T(), stmt.Err())
+	} else {
+		assert.Equal(suite.T(), qb.ErrUnsupported, stmt.Err().(qb.Error).Code)
+	}
+}
+
+func (suite *SqliteTestSuite) TestConditionals() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("score", qb.Int()),
+	)
+	suite.metadata.AddTable(users)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(qb.Insert(users).Rows(
+		map[string]interface{}{"id": 1, "email": "Al@Pacino.com", "score": 10},
+		map[string]interface{}{"id": 2, "email": "robert@deniro.com", "score": nil},
+		map[string]interface{}{"id": 3, "email": "jack@nicholson.com", "score": 30},
+	))
+	assert.Nil(suite.T(), err)
+
+	var tests = []struct {
+		where    qb.Clause
+		expected []int
+	}{
+		{users.C("score").Eq(nil), []int{2}},
+		{users.C("score").NotEq(nil), []int{1, 3}},
+		{users.C("score").Between(5, 20), []int{1}},
+		{users.C("score").NotBetween(5, 20), []int{3}},
+		{users.C("email").ILike("al@%"), []int{1}},
+		{users.C("email").NotLike("%.com"), nil},
+		{qb.Not(users.C("email").Like("%nicholson%")), []int{1, 2}},
+	}
+	for _, tt := range tests {
+		var ids []int
+		err := suite.engine.Select(qb.Select(users.C("id")).From(users).Where(tt.where).OrderBy(users.C("id")), &ids)
+		assert.Nil(suite.T(), err)
+		assert.Equal(suite.T(), tt.expected, ids)
+	}
+}
+
+func (suite *SqliteTestSuite) TestMultiTable() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("active", qb.Boolean()),
+		qb.Column("session_id", qb.Int()),
+	)
+	sessions := qb.Table(
+		"sessions",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("user_id", qb.Int()),
+	)
+
+	// UPDATE ... FROM requires sqlite 3.33, it is emulated before
+	upd := qb.Update(users).
+		OrderedValues(qb.Value("active", true), qb.Value("session_id", sessions.C("id"))).
+		From(sessions).
+		Where(qb.And(users.C("id").Eq(sessions.C("user_id")), sessions.C("id").NotEq(3)))
+	ctx := qb.NewCompilerContext(NewDialect())
+	sql := upd.Accept(ctx)
+	assert.Empty(suite.T(), ctx.Errors())
+	if NewDialect().Features().Has(qb.FeatureUpdateFrom) {
+		assert.Equal(suite.T(), strings.Join([]string{
+			"UPDATE users",
+			"SET active = ?, session_id = sessions.id",
+			"FROM sessions",
+			"WHERE (users.id = sessions.user_id AND sessions.id != ?)",
+		}, "\n"), sql)
+	} else {
+		assert.Equal(suite.T(), strings.Join([]string{
+			"UPDATE users",
+			"SET active = ?, session_id = (SELECT sessions.id",
+			"FROM sessions",
+			"WHERE (users.id = sessions.user_id AND sessions.id != ?))",
+			"WHERE EXISTS(SELECT 1",
+			"FROM sessions",
+			"WHERE (users.id = sessions.user_id AND sessions.id != ?))",
+		}, "\n"), sql)
+	}
+
+	suite.metadata.AddTable(users)
+	suite.metadata.AddTable(sessions)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(qb.Insert(users).Rows(
+		map[string]interface{}{"id": 1, "active": true},
+		map[string]interface{}{"id": 2, "active": false},
+		map[string]interface{}{"id": 3, "active": false},
+	))
+	assert.Nil(suite.T(), err)
+	_, err = suite.engine.Exec(qb.Insert(sessions).Rows(
+		map[string]interface{}{"id": 1, "user_id": 1},
+		map[string]interface{}{"id": 2, "user_id": 2},
+		map[string]interface{}{"id": 3, "user_id": 2},
+	))
+	assert.Nil(suite.T(), err)
+
+	res, err := suite.engine.Exec(upd)
+	assert.Nil(suite.T(), err)
+	rowsAffected, err := res.RowsAffected()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), rowsAffected)
+	type User struct {
+		ID        int
+		Active    bool
+		SessionID *int64 `db:"session_id"`
+	}
+	var updated []User
+	assert.Nil(suite.T(), suite.engine.Select(qb.Select(users.C("id"), users.C("active"), users.C("session_id")).
+		From(users).
+		OrderBy(users.C("id")), &updated))
+	first, second := int64(1), int64(2)
+	assert.Equal(suite.T(), []User{
+		{ID: 1, Active: true, SessionID: &first},
+		{ID: 2, Active: true, SessionID: &second},
+		{ID: 3},
+	}, updated)
+	_, err = suite.engine.Exec(qb.Update(users).Values(map[string]interface{}{"active": false}).Where(users.C("id").Eq(2)))
+	assert.Nil(suite.T(), err)
+
+	del := qb.Delete(sessions).
+		Using(users).
+		Where(qb.And(sessions.C("user_id").Eq(users.C("id")), users.C("active").Eq(false)))
+
+	ctx = qb.NewCompilerContext(NewDialect())
+	assert.Equal(suite.T(), strings.Join([]string{
+		"DELETE FROM sessions",
+		"WHERE EXISTS(SELECT 1",
+		"FROM users",
+		"WHERE (sessions.user_id = users.id AND users.active = ?))",
+	}, "\n"), del.Accept(ctx))
+
+	res, err = suite.engine.Exec(del)
+	assert.Nil(suite.T(), err)
+	rowsAffected, err = res.RowsAffected()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), rowsAffected)
+}
+
+func (suite *SqliteTestSuite) TestUpdateExpressions() {
+	counters := qb.Table(
+		"counters",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("value", qb.Int()),
+	)
+	suite.metadata.AddTable(counters)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(qb.Insert(counters).Values(map[string]interface{}{"id": 1, "value": 10}))
+	assert.Nil(suite.T(), err)
+
+	_, err = suite.engine.Exec(qb.Update(counters).
+		Values(map[string]interface{}{"value": qb.Add(counters.C("value").Mul(2), 1)}).
+		Where(counters.C("id").Eq(1)))
+	assert.Nil(suite.T(), err)
+
+	var value int
+	err = suite.engine.QueryRow(qb.Select(counters.C("value").Neg()).From(counters)).Scan(&value)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), -21, value)
+}
+
+func (suite *SqliteTestSuite) TestAlterTable() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("name", qb.Varchar()),
+		qb.Index("users", "email"),
+	)
+	suite.metadata.AddTable(users)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+
+	_, err := suite.engine.Exec(qb.Insert(users).Rows(
+		map[string]interface{}{"id": 1, "email": "al@pacino.com", "name": "Al"},
+		map[string]interface{}{"id": 2, "email": "robert@deniro.com", "name": "Robert"},
+	))
+	assert.Nil(suite.T(), err)
+
+	alter := qb.AlterTable(users).
+		AddColumn(qb.Column("age", qb.Int())).
+		RenameColumn("name", "full_name")
+	assert.Equal(suite.T(),
+		"ALTER TABLE users ADD COLUMN age INT;\n"+
+			"ALTER TABLE users RENAME COLUMN name TO full_name;",
+		alter.Build(suite.engine.Dialect()).SQL())
+	_, err = suite.engine.Exec(alter)
+	assert.Nil(suite.T(), err)
+
+	users = alter.Altered()
+	alter = qb.AlterTable(users).
+		DropColumn("age").
+		SetNotNull("email").
+		AddIndex(qb.Index("", "full_name"))
+	assert.Equal(suite.T(),
+		"CREATE TABLE qb_tmp_users (\n"+
+			"\tid INT PRIMARY KEY,\n"+
+			"\temail VARCHAR(255) NOT NULL,\n"+
+			"\tfull_name VARCHAR(255)\n"+
+			");\n"+
+			"INSERT INTO qb_tmp_users(id, email, full_name) SELECT id, email, full_name FROM users;\n"+
+			"DROP TABLE users;\n"+
+			"ALTER TABLE qb_tmp_users RENAME TO users;\n"+
+			"CREATE INDEX i_users_email ON users(email);\n"+
+			"CREATE INDEX i_users_full_name ON users(full_name);",
+		alter.Build(suite.engine.Dialect()).SQL())
+	_, err = suite.engine.Exec(alter)
+	assert.Nil(suite.T(), err)
+
+	users = alter.Altered()
+	defer suite.engine.DB().Exec(users.Drop(suite.engine.Dialect()))
+
+	var names []string
+	err = suite.engine.Select(qb.Select(users.C("full_name")).From(users).OrderBy(users.C("id")), &names)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Al", "Robert"}, names)
+
+	_, err = suite.engine.Exec(qb.Insert(users).Values(map[string]interface{}{"id": 3, "full_name": "Jack"}))
+	assert.Equal(suite.T(), qb.ErrIntegrity, err.(qb.Error).Code)
+
+	var indexes []string
+	err = suite.engine.DB().Select(&indexes, "SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'users' AND sql IS NOT NULL ORDER BY name")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"i_users_email", "i_users_full_name"}, indexes)
+}
+
+func (suite *SqliteTestSuite) TestAlterTableChecks() {
+	age := qb.Column("age", qb.Int())
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		age.Check(age.Gt(0)),
+		qb.Column("name", qb.Varchar()),
+		qb.Check(age.Lt(150)).Name("realistic_age"),
+	)
+	suite.metadata.AddTable(users)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+
+	insert := func(table qb.TableElem, values map[string]interface{}) error {
+		_, err := suite.engine.Exec(table.Insert().Values(values))
+		return err
+	}
+
+	// the checks follow the renamed column
+	alter := qb.AlterTable(users).RenameColumn("age", "years").AlterColumnType("years", qb.BigInt())
+	_, err := suite.engine.Exec(alter)
+	assert.Nil(suite.T(), err)
+	users = alter.Altered()
+	defer suite.engine.DB().Exec(users.Drop(suite.engine.Dialect()))
+	assert.Nil(suite.T(), insert(users, map[string]interface{}{"id": 1, "years": 20, "name": "Jack"}))
+	assert.Equal(suite.T(), qb.ErrIntegrity, insert(users, map[string]interface{}{"id": 2, "years": -1}).(qb.Error).Code)
+	assert.Equal(suite.T(), qb.ErrIntegrity, insert(users, map[string]interface{}{"id": 2, "years": 200}).(qb.Error).Code)
+
+	// the checks of a dropped column are dropped
+	alter = qb.AlterTable(users).DropColumn("years").SetNotNull("name")
+	_, err = suite.engine.Exec(alter)
+	assert.Nil(suite.T(), err)
+	users = alter.Altered()
+	assert.Empty(suite.T(), users.CheckConstraints)
+	assert.Nil(suite.T(), insert(users, map[string]interface{}{"id": 2, "name": "Al"}))
+}
+
+func (suite *SqliteTestSuite) TestMetaDataCycleForeignKeys() {
+	engine, err := qb.New("sqlite3", "./qb_fk_test.db?_foreign_keys=1")
+	assert.Nil(suite.T(), err)
+	defer os.Remove("./qb_fk_test.db")
+	defer engine.Close()
+
+	metadata := qb.MetaData()
+	metadata.AddTable(qb.Table(
+		"a",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("b_id", qb.Int()),
+		qb.ForeignKey("b_id").References("b", "id"),
+	))
+	metadata.AddTable(qb.Table(
+		"b",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("a_id", qb.Int()),
+		qb.ForeignKey("a_id").References("a", "id"),
+	))
+	assert.Nil(suite.T(), metadata.CreateAll(engine))
+
+	var definition string
+	assert.Nil(suite.T(), engine.DB().Get(&definition, "SELECT sql FROM sqlite_master WHERE name = 'b'"))
+	assert.Contains(suite.T(), definition, "REFERENCES a(id)")
+	_, err = engine.Exec(metadata.Table("a").Insert().Values(map[string]interface{}{"id": 1, "b_id": 1}))
+	assert.Equal(suite.T(), qb.ErrIntegrity, err.(qb.Error).Code)
+
+	assert.Nil(suite.T(), metadata.DropAll(engine))
+}
+
+func (suite *SqliteTestSuite) TestAlterTableForeignKeys() {
+	engine, err := qb.New("sqlite3", "./qb_fk_test.db?_foreign_keys=1")
+	assert.Nil(suite.T(), err)
+	defer os.Remove("./qb_fk_test.db")
+	defer engine.Close()
+
+	teams := qb.Table(
+		"teams",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("name", qb.Varchar()),
+	)
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("team_id", qb.Int()),
+		qb.ForeignKey("team_id").References("teams", "id"),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(teams)
+	metadata.AddTable(users)
+	assert.Nil(suite.T(), metadata.CreateAll(engine))
+
+	_, err = engine.Exec(teams.Insert().Values(map[string]interface{}{"id": 1, "name": "qb"}))
+	assert.Nil(suite.T(), err)
+	_, err = engine.Exec(users.Insert().Values(map[string]interface{}{"id": 1, "team_id": 1}))
+	assert.Nil(suite.T(), err)
+
+	// dropping the referenced table would fail with the foreign keys enabled
+	_, err = engine.Exec(qb.AlterTable(teams).SetNotNull("name"))
+	assert.Nil(suite.T(), err)
+	var count int
+	assert.Nil(suite.T(), engine.QueryRow(qb.Select(qb.Count(users.C("id"))).From(users)).Scan(&count))
+	assert.Equal(suite.T(), 1, count)
+
+	// the foreign keys are enabled again
+	_, err = engine.Exec(users.Insert().Values(map[string]interface{}{"id": 2, "team_id": 2}))
+	assert.Equal(suite.T(), qb.ErrIntegrity, err.(qb.Error).Code)
+
+	err = engine.Transaction(func(tx *qb.Tx) error {
+		_, err := tx.Exec(qb.AlterTable(teams).DropNotNull("name"))
+		return err
+	})
+	assert.Equal(suite.T(), qb.ErrUnsupported, err.(qb.Error).Code)
+
+	// the rebuild is rolled back if it breaks a foreign key
+	members := qb.Table("members", qb.Column("id", qb.Int()).PrimaryKey(), qb.Column("team_id", qb.Int()))
+	_, err = engine.Exec(members)
+	assert.Nil(suite.T(), err)
+	_, err = engine.Exec(members.Insert().Values(map[string]interface{}{"id": 1, "team_id": 2}))
+	assert.Nil(suite.T(), err)
+	_, err = engine.Exec(qb.AlterTable(members).AddConstraint(qb.ForeignKey("team_id").References("teams", "id")))
+	assert.Equal(suite.T(), qb.ErrIntegrity, err.(qb.Error).Code)
+	var definition string
+	assert.Nil(suite.T(), engine.DB().Get(&definition, "SELECT sql FROM sqlite_master WHERE name = 'members'"))
+	assert.NotContains(suite.T(), definition, "REFERENCES")
+}
+
+func (suite *SqliteTestSuite) TestDDLOptions() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+		qb.Index("users", "email"),
+	)
+	suite.metadata.AddTable(users)
+
+	// the tables are created and dropped in a transaction
+	err := suite.metadata.CreateAll(suite.engine, qb.Concurrently)
+	assert.Equal(suite.T(), qb.ErrCompile, err.(qb.Error).Code)
+	err = suite.metadata.DropAll(suite.engine, qb.Concurrently)
+	assert.Equal(suite.T(), qb.ErrCompile, err.(qb.Error).Code)
+
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	assert.NotNil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine, qb.IfNotExists))
+
+	err = suite.metadata.DropAll(suite.engine, qb.Cascade)
+	assert.Equal(suite.T(), qb.ErrUnsupported, err.(qb.Error).Code)
+
+	assert.Nil(suite.T(), suite.metadata.DropAll(suite.engine))
+	assert.NotNil(suite.T(), suite.metadata.DropAll(suite.engine))
+	assert.Nil(suite.T(), suite.metadata.DropAll(suite.engine, qb.IfExists))
+}
+
+func (suite *SqliteTestSuite) TestIndexes() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("deleted", qb.Boolean()),
+	)
+	unique := qb.UniqueIndex("users", "email").Where(users.C("deleted").Eq(false))
+	lower := qb.Index("users", "id").Desc("id").Expr(qb.Aggregate("LOWER", users.C("email")))
+	users = qb.Table("users", users.C("id"), users.C("email"), users.C("deleted"), unique, lower)
+
+	assert.Equal(suite.T(),
+		"CREATE UNIQUE INDEX i_users_email ON users(email) WHERE deleted = FALSE",
+		unique.Create(NewDialect()))
+	assert.Equal(suite.T(),
+		"CREATE INDEX i_users_id_expr_9283c2fe ON users(id DESC, (LOWER(email)))",
+		lower.Create(NewDialect()))
+
+	suite.metadata.AddTable(users)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+
+	insert := func(id int, email string, deleted bool) error {
+		_, err := suite.engine.Exec(users.Insert().Values(map[string]interface{}{
+			"id": id, "email": email, "deleted": deleted,
+		}))
+		return err
+	}
+	assert.Nil(suite.T(), insert(1, "jn@slicebit.com", true))
+	assert.Nil(suite.T(), insert(2, "jn@slicebit.com", false))
+	assert.NotNil(suite.T(), insert(3, "jn@slicebit.com", false))
+
+	_, err := suite.engine.Exec(qb.DropIndex("users", "i_users_email"))
+	assert.Nil(suite.T(), err)
+	_, err = suite.engine.Exec(qb.DropIndex("users", "i_users_email"))
+	assert.NotNil(suite.T(), err)
+	_, err = suite.engine.Exec(qb.DropIndex("users", "i_users_email", qb.IfExists))
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), insert(3, "jn@slicebit.com", false))
+
+	_, err = suite.engine.Exec(qb.Index("users", "email").Using("hash"))
+	assert.Equal(suite.T(), qb.ErrUnsupported, err.(qb.Error).Code)
+
+	assert.Nil(suite.T(), suite.metadata.DropAll(suite.engine))
+}
+
+func (suite *SqliteTestSuite) TestCreateAllOrder() {
+	suite.metadata.AddTable(qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("team_id", qb.Int()),
+		qb.ForeignKey("team_id").References("teams", "id"),
+	))
+	suite.metadata.AddTable(qb.Table(
+		"teams",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("owner_id", qb.Int()),
+		qb.ForeignKey("owner_id").References("users", "id"),
+	))
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+
+	var refs []string
+	err := suite.engine.DB().Select(&refs, `SELECT "table" FROM pragma_foreign_key_list('teams')`)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"users"}, refs)
+
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine, qb.IfNotExists))
+	assert.Nil(suite.T(), suite.metadata.DropAll(suite.engine))
+	assert.Nil(suite.T(), suite.metadata.DropAll(suite.engine, qb.IfExists))
+
+	// a failure rolls the whole creation back
+	suite.metadata.AddTable(qb.Table("broken", qb.Column("id", qb.Int()).Constraint("DEFAULT")))
+	assert.NotNil(suite.T(), suite.metadata.CreateAll(suite.engine))
+
+	var tables []string
+	err = suite.engine.DB().Select(&tables, "SELECT name FROM sqlite_master WHERE type = 'table'")
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), tables)
+}
+
+func (suite *SqliteTestSuite) TestConstraints() {
+	age := qb.Column("age", qb.Int())
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("nick", qb.Varchar()),
+		age.Check(age.Gte(0)),
+		qb.UniqueKey("email"),
+		qb.UniqueKey("nick"),
+		qb.Check(age.Lt(150)).Name("realistic_age"),
+	)
+	suite.metadata.AddTable(users)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine, qb.IfExists)
+
+	insert := func(id int, email string, nick string, age int) error {
+		_, err := suite.engine.Exec(qb.Insert(users).Values(map[string]interface{}{
+			"id": id, "email": email, "nick": nick, "age": age,
+		}))
+		return err
+	}
+	assert.Nil(suite.T(), insert(1, "al@pacino.com", "al", 20))
+	assert.Equal(suite.T(), qb.ErrIntegrity, insert(2, "al@pacino.com", "bob", 20).(qb.Error).Code)
+	assert.Equal(suite.T(), qb.ErrIntegrity, insert(2, "bob@pacino.com", "al", 20).(qb.Error).Code)
+	assert.Equal(suite.T(), qb.ErrIntegrity, insert(2, "bob@pacino.com", "bob", -1).(qb.Error).Code)
+	assert.Equal(suite.T(), qb.ErrIntegrity, insert(2, "bob@pacino.com", "bob", 200).(qb.Error).Code)
+
+	_, err := suite.engine.Exec(qb.AlterTable(users).DropConstraint("realistic_age"))
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), insert(2, "bob@pacino.com", "bob", 200))
+}
+
 func TestSqliteTestSuite(t *testing.T) {
 	suite.Run(t, new(SqliteTestSuite))
 }
@@ -1,7 +1,9 @@
 package qb
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
 
@@ -9,8 +11,35 @@ import (
 	"github.com/serenize/snaker"
 )
 
+// Executor is the common interface of Engine and Tx for running statements
+// It allows writing code that is agnostic of whether it runs inside a
+// transaction or not
+type Executor interface {
+	Exec(builder Builder) (sql.Result, error)
+	ExecContext(ctx context.Context, builder Builder) (sql.Result, error)
+	Query(builder Builder) (*sql.Rows, error)
+	QueryContext(ctx context.Context, builder Builder) (*sql.Rows, error)
+	QueryRow(builder Builder) Row
+	QueryRowContext(ctx context.Context, builder Builder) Row
+	Get(builder Builder, model interface{}) error
+	GetContext(ctx context.Context, builder Builder, model interface{}) error
+	Select(builder Builder, model interface{}) error
+	SelectContext(ctx context.Context, builder Builder, model interface{}) error
+}
+
 // New generates a new engine and returns it as an engine pointer
 func New(driver string, dsn string) (*Engine, error) {
+	dialect, err := GetDialect(driver)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithDialect(driver, dsn, dialect)
+}
+
+// NewWithDialect generates a new engine using the given dialect instead of
+// the one registered for the driver
+// engine, err := qb.NewWithDialect("mysql", dsn, mysql.NewMariaDBDialect())
+func NewWithDialect(driver string, dsn string, dialect Dialect) (*Engine, error) {
 	conn, err := sqlx.Open(driver, dsn)
 	if err != nil {
 		return nil, err
@@ -22,7 +51,7 @@ func New(driver string, dsn string) (*Engine, error) {
 	})
 
 	return &Engine{
-		dialect: NewDialect(driver),
+		dialect: dialect,
 		dsn:     dsn,
 		db:      conn,
 		logger:  &DefaultLogger{LDefault, log.New(os.Stdout, "", -1)},
@@ -48,7 +77,11 @@ func (e Engine) SetDialect(dialect Dialect) {
 }
 
 // TranslateError translates the native errors into qb.Error
+// A qb.Error is returned as-is
 func (e Engine) TranslateError(err error) error {
+	if _, ok := err.(Error); ok {
+		return err
+	}
 	if err != nil {
 		return e.dialect.WrapError(err)
 	}
@@ -82,9 +115,23 @@ func (e *Engine) log(statement *Stmt) {
 
 // Exec executes insert & update type queries and returns sql.Result and error
 func (e *Engine) Exec(builder Builder) (sql.Result, error) {
+	return e.ExecContext(context.Background(), builder)
+}
+
+// ExecContext executes insert & update type queries using the given context
+// and returns sql.Result and error
+// The statements of the builders generating several sql statements, such as
+// AlterTableStmt, are executed one by one in a transaction
+func (e *Engine) ExecContext(ctx context.Context, builder Builder) (sql.Result, error) {
+	if script, ok := builder.(statementsBuilder); ok {
+		return e.execStatements(ctx, script)
+	}
 	statement := builder.Build(e.dialect)
+	if err := statement.Err(); err != nil {
+		return nil, err
+	}
 	e.log(statement)
-	res, err := e.db.Exec(statement.SQL(), statement.Bindings()...)
+	res, err := e.db.ExecContext(ctx, statement.SQL(), statement.Bindings()...)
 	return res, e.TranslateError(err)
 }
 
@@ -92,45 +139,117 @@ func (e *Engine) Exec(builder Builder) (sql.Result, error) {
 type Row struct {
 	*sql.Row
 	TranslateError func(error) error
+	err            error
 }
 
 // Scan wraps sql.Row.Scan()
 func (r Row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
 	return r.TranslateError(r.Row.Scan(dest...))
 }
 
 // QueryRow wraps *sql.DB.QueryRow()
 func (e *Engine) QueryRow(builder Builder) Row {
+	return e.QueryRowContext(context.Background(), builder)
+}
+
+// QueryRowContext wraps *sql.DB.QueryRowContext()
+// RETURNING columns the dialect does not support are emulated on a dedicated
+// connection, returned to the pool once the row is scanned
+func (e *Engine) QueryRowContext(ctx context.Context, builder Builder) Row {
+	if emulatesReturning(e.dialect, builder) {
+		conn, statement, err := e.emulateReturningOnConn(ctx, builder)
+		if err != nil {
+			return Row{err: err}
+		}
+		row := conn.QueryRowContext(ctx, statement.SQL(), statement.Bindings()...)
+		// Close blocks until the row is scanned
+		go conn.Close()
+		return Row{Row: row, TranslateError: e.TranslateError}
+	}
 	statement := builder.Build(e.dialect)
+	if err := statement.Err(); err != nil {
+		return Row{err: err}
+	}
 	e.log(statement)
 	return Row{
-		e.db.QueryRow(statement.SQL(), statement.Bindings()...),
-		e.TranslateError,
+		Row:            e.db.QueryRowContext(ctx, statement.SQL(), statement.Bindings()...),
+		TranslateError: e.TranslateError,
 	}
 }
 
 // Query wraps *sql.DB.Query()
 func (e *Engine) Query(builder Builder) (*sql.Rows, error) {
+	return e.QueryContext(context.Background(), builder)
+}
+
+// QueryContext wraps *sql.DB.QueryContext()
+// RETURNING columns the dialect does not support are emulated on a dedicated
+// connection, returned to the pool once the rows are closed
+func (e *Engine) QueryContext(ctx context.Context, builder Builder) (*sql.Rows, error) {
+	if emulatesReturning(e.dialect, builder) {
+		conn, statement, err := e.emulateReturningOnConn(ctx, builder)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := conn.QueryContext(ctx, statement.SQL(), statement.Bindings()...)
+		// Close blocks until the rows are closed
+		go conn.Close()
+		return rows, e.TranslateError(err)
+	}
 	statement := builder.Build(e.dialect)
+	if err := statement.Err(); err != nil {
+		return nil, err
+	}
 	e.log(statement)
-	rows, err := e.db.Query(statement.SQL(), statement.Bindings()...)
+	rows, err := e.db.QueryContext(ctx, statement.SQL(), statement.Bindings()...)
 	return rows, e.TranslateError(err)
 }
 
 // Get maps the single row to a model
 func (e *Engine) Get(builder Builder, model interface{}) error {
+	return e.GetContext(context.Background(), builder, model)
+}
+
+// GetContext maps the single row to a model using the given context
+// RETURNING columns the dialect does not support are emulated in a transaction
+func (e *Engine) GetContext(ctx context.Context, builder Builder, model interface{}) error {
+	if emulatesReturning(e.dialect, builder) {
+		return e.TransactionContext(ctx, nil, func(tx *Tx) error {
+			return tx.GetContext(ctx, builder, model)
+		})
+	}
 	statement := builder.Build(e.dialect)
+	if err := statement.Err(); err != nil {
+		return err
+	}
 	e.log(statement)
 	return e.TranslateError(
-		e.db.Get(model, statement.SQL(), statement.Bindings()...))
+		e.db.GetContext(ctx, model, statement.SQL(), statement.Bindings()...))
 }
 
 // Select maps multiple rows to a model array
 func (e *Engine) Select(builder Builder, model interface{}) error {
+	return e.SelectContext(context.Background(), builder, model)
+}
+
+// SelectContext maps multiple rows to a model array using the given context
+// RETURNING columns the dialect does not support are emulated in a transaction
+func (e *Engine) SelectContext(ctx context.Context, builder Builder, model interface{}) error {
+	if emulatesReturning(e.dialect, builder) {
+		return e.TransactionContext(ctx, nil, func(tx *Tx) error {
+			return tx.SelectContext(ctx, builder, model)
+		})
+	}
 	statement := builder.Build(e.dialect)
+	if err := statement.Err(); err != nil {
+		return err
+	}
 	e.log(statement)
 	return e.TranslateError(
-		e.db.Select(model, statement.SQL(), statement.Bindings()...))
+		e.db.SelectContext(ctx, model, statement.SQL(), statement.Bindings()...))
 }
 
 // DB returns sql.DB of wrapped engine connection
@@ -160,17 +279,74 @@ func (e *Engine) Dsn() string {
 
 // Begin begins a transaction and return a *qb.Tx
 func (e *Engine) Begin() (*Tx, error) {
-	tx, err := e.db.Beginx()
+	return e.BeginTx(context.Background(), nil)
+}
+
+// BeginTx begins a transaction with the given context and options and
+// return a *qb.Tx
+// The context is used until the transaction is committed or rolled back.
+func (e *Engine) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := e.db.BeginTxx(ctx, opts)
 	if err != nil {
 		return nil, e.dialect.WrapError(err)
 	}
-	return &Tx{e, tx}, nil
+	return &Tx{engine: e, tx: tx}, nil
+}
+
+// Transaction runs fn inside a transaction
+// The transaction is committed if fn returns nil, and rolled back if it
+// returns an error or panics. In the latter case the panic is propagated
+// once the transaction is rolled back.
+func (e *Engine) Transaction(fn func(tx *Tx) error) error {
+	return e.TransactionContext(context.Background(), nil, fn)
+}
+
+// TransactionContext is like Transaction but begins the transaction with
+// the given context and options
+func (e *Engine) TransactionContext(ctx context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) (err error) {
+	tx, err := e.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// InsertBatch executes a multi-row insert statement in a transaction
+// The rows are split in as many statements as needed to stay below the bind
+// parameters and the statement size limits of the dialect. If batchSize is
+// positive, each statement inserts at most batchSize rows.
+// It returns the total number of inserted rows
+func (e *Engine) InsertBatch(insert InsertStmt, batchSize int) (int64, error) {
+	return e.InsertBatchContext(context.Background(), insert, batchSize)
+}
+
+// InsertBatchContext is like InsertBatch but uses the given context
+func (e *Engine) InsertBatchContext(ctx context.Context, insert InsertStmt, batchSize int) (count int64, err error) {
+	err = e.TransactionContext(ctx, nil, func(tx *Tx) error {
+		count, err = tx.InsertBatchContext(ctx, insert, batchSize)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
 }
 
 // Tx is an in-progress database transaction
 type Tx struct {
-	engine *Engine
-	tx     *sqlx.Tx
+	engine     *Engine
+	tx         *sqlx.Tx
+	savepoints int
 }
 
 // Tx returns the underlying *sqlx.Tx
@@ -180,52 +356,183 @@ func (tx *Tx) Tx() *sqlx.Tx {
 
 // Commit commits the transaction
 func (tx *Tx) Commit() error {
-	return tx.tx.Commit()
+	return tx.engine.TranslateError(tx.tx.Commit())
 }
 
 // Rollback aborts the transaction
 func (tx *Tx) Rollback() error {
-	return tx.tx.Rollback()
+	return tx.engine.TranslateError(tx.tx.Rollback())
+}
+
+// Savepoint establishes a new savepoint in the transaction
+func (tx *Tx) Savepoint(name string) error {
+	_, err := tx.Exec(Savepoint(name))
+	return err
+}
+
+// RollbackTo rolls back the transaction to the given savepoint
+func (tx *Tx) RollbackTo(name string) error {
+	_, err := tx.Exec(RollbackToSavepoint(name))
+	return err
+}
+
+// Release destroys the given savepoint, keeping the effects of the
+// statements executed after it was established
+func (tx *Tx) Release(name string) error {
+	_, err := tx.Exec(ReleaseSavepoint(name))
+	return err
+}
+
+// Transaction runs fn in a nested transaction, using a savepoint
+// The savepoint is released if fn returns nil, and the transaction is rolled
+// back to it if fn returns an error or panics. In the latter case the panic
+// is propagated once the savepoint is rolled back.
+func (tx *Tx) Transaction(fn func(tx *Tx) error) (err error) {
+	tx.savepoints++
+	name := fmt.Sprintf("qb_savepoint_%d", tx.savepoints)
+	if err = tx.Savepoint(name); err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.RollbackTo(name)
+			panic(r)
+		}
+	}()
+	if err = fn(tx); err != nil {
+		tx.RollbackTo(name)
+		return err
+	}
+	return tx.Release(name)
 }
 
 // Exec executes insert & update type queries and returns sql.Result and error
 func (tx *Tx) Exec(builder Builder) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), builder)
+}
+
+// ExecContext executes insert & update type queries using the given context
+// and returns sql.Result and error
+// The statements of the builders generating several sql statements, such as
+// AlterTableStmt, are executed one by one
+func (tx *Tx) ExecContext(ctx context.Context, builder Builder) (sql.Result, error) {
+	if script, ok := builder.(statementsBuilder); ok {
+		return tx.execStatements(ctx, script)
+	}
 	statement := builder.Build(tx.engine.dialect)
+	if err := statement.Err(); err != nil {
+		return nil, err
+	}
 	tx.engine.log(statement)
-	res, err := tx.tx.Exec(statement.SQL(), statement.Bindings()...)
+	res, err := tx.tx.ExecContext(ctx, statement.SQL(), statement.Bindings()...)
 	return res, tx.engine.TranslateError(err)
 }
 
+// InsertBatch executes a multi-row insert statement, split in as many
+// statements as needed to stay below the bind parameters and the statement
+// size limits of the dialect.
+// If batchSize is positive, each statement inserts at most batchSize rows.
+// It returns the total number of inserted rows
+func (tx *Tx) InsertBatch(insert InsertStmt, batchSize int) (int64, error) {
+	return tx.InsertBatchContext(context.Background(), insert, batchSize)
+}
+
+// InsertBatchContext is like InsertBatch but uses the given context
+func (tx *Tx) InsertBatchContext(ctx context.Context, insert InsertStmt, batchSize int) (int64, error) {
+	var count int64
+	dialect := tx.engine.dialect
+	for _, chunk := range insert.chunks(dialect.MaxBindParams(), dialect.MaxPacketSize(), batchSize) {
+		res, err := tx.ExecContext(ctx, chunk)
+		if err != nil {
+			return count, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return count, tx.engine.TranslateError(err)
+		}
+		count += n
+	}
+	return count, nil
+}
+
 // QueryRow wraps *sql.DB.QueryRow()
 func (tx *Tx) QueryRow(builder Builder) Row {
+	return tx.QueryRowContext(context.Background(), builder)
+}
+
+// QueryRowContext wraps *sql.Tx.QueryRowContext()
+func (tx *Tx) QueryRowContext(ctx context.Context, builder Builder) Row {
+	builder, err := emulateReturning(ctx, tx.engine.dialect, tx.ExecContext, builder)
+	if err != nil {
+		return Row{err: err}
+	}
 	statement := builder.Build(tx.engine.dialect)
+	if err := statement.Err(); err != nil {
+		return Row{err: err}
+	}
 	tx.engine.log(statement)
 	return Row{
-		tx.tx.QueryRow(statement.SQL(), statement.Bindings()...),
-		tx.engine.TranslateError,
+		Row:            tx.tx.QueryRowContext(ctx, statement.SQL(), statement.Bindings()...),
+		TranslateError: tx.engine.TranslateError,
 	}
 }
 
 // Query wraps *sql.DB.Query()
 func (tx *Tx) Query(builder Builder) (*sql.Rows, error) {
+	return tx.QueryContext(context.Background(), builder)
+}
+
+// QueryContext wraps *sql.Tx.QueryContext()
+func (tx *Tx) QueryContext(ctx context.Context, builder Builder) (*sql.Rows, error) {
+	builder, err := emulateReturning(ctx, tx.engine.dialect, tx.ExecContext, builder)
+	if err != nil {
+		return nil, err
+	}
 	statement := builder.Build(tx.engine.dialect)
+	if err := statement.Err(); err != nil {
+		return nil, err
+	}
 	tx.engine.log(statement)
-	rows, err := tx.tx.Query(statement.SQL(), statement.Bindings()...)
+	rows, err := tx.tx.QueryContext(ctx, statement.SQL(), statement.Bindings()...)
 	return rows, tx.engine.TranslateError(err)
 }
 
 // Get maps the single row to a model
 func (tx *Tx) Get(builder Builder, model interface{}) error {
+	return tx.GetContext(context.Background(), builder, model)
+}
+
+// GetContext maps the single row to a model using the given context
+func (tx *Tx) GetContext(ctx context.Context, builder Builder, model interface{}) error {
+	builder, err := emulateReturning(ctx, tx.engine.dialect, tx.ExecContext, builder)
+	if err != nil {
+		return err
+	}
 	statement := builder.Build(tx.engine.dialect)
+	if err := statement.Err(); err != nil {
+		return err
+	}
 	tx.engine.log(statement)
 	return tx.engine.TranslateError(
-		tx.tx.Get(model, statement.SQL(), statement.Bindings()...))
+		tx.tx.GetContext(ctx, model, statement.SQL(), statement.Bindings()...))
 }
 
 // Select maps multiple rows to a model array
 func (tx *Tx) Select(builder Builder, model interface{}) error {
+	return tx.SelectContext(context.Background(), builder, model)
+}
+
+// SelectContext maps multiple rows to a model array using the given context
+func (tx *Tx) SelectContext(ctx context.Context, builder Builder, model interface{}) error {
+	builder, err := emulateReturning(ctx, tx.engine.dialect, tx.ExecContext, builder)
+	if err != nil {
+		return err
+	}
 	statement := builder.Build(tx.engine.dialect)
+	if err := statement.Err(); err != nil {
+		return err
+	}
 	tx.engine.log(statement)
 	return tx.engine.TranslateError(
-		tx.tx.Select(model, statement.SQL(), statement.Bindings()...))
+		tx.tx.SelectContext(ctx, model, statement.SQL(), statement.Bindings()...))
 }
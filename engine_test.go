@@ -1,6 +1,9 @@
 package qb_test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -21,9 +24,26 @@ func TestEngine(t *testing.T) {
 func TestInvalidEngine(t *testing.T) {
 	engine, err := qb.New("invalid", "")
 	assert.NotEqual(t, nil, err)
+	assert.Equal(t, qb.ErrInterface, err.(qb.Error).Code)
 	assert.Equal(t, (*qb.Engine)(nil), engine)
 }
 
+func TestEngineCompileError(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+
+	metadata := qb.MetaData()
+	users := qb.Table("users", qb.Column("id", qb.Int()).PrimaryKey())
+	metadata.AddTable(users)
+
+	_, err = engine.Exec(qb.Insert(users).Rows(42))
+	assert.Equal(t, qb.ErrCompile, err.(qb.Error).Code)
+
+	var ids []int
+	err = engine.Select(qb.Select(users.C("id")).From(metadata.Table("missing")), &ids)
+	assert.Equal(t, qb.CompileError("Table missing not found"), err)
+}
+
 func TestEngineExec(t *testing.T) {
 	engine, err := qb.New("sqlite3", ":memory:")
 	dialect := qb.NewDialect("sqlite")
@@ -187,3 +207,307 @@ func TestEngineSelect(t *testing.T) {
 	assert.Equal(t, 1, len(s))
 	assert.Equal(t, 1, s[0].Value)
 }
+
+func TestEngineContext(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+
+	usersTable := qb.Table(
+		"users",
+		qb.Column("full_name", qb.Varchar()).NotNull(),
+	)
+
+	_, err = engine.DB().Exec(usersTable.Create(engine.Dialect()))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	_, err = engine.ExecContext(ctx, usersTable.Insert().
+		Values(map[string]interface{}{
+			"full_name": "Robert De Niro",
+		}),
+	)
+	assert.Nil(t, err)
+
+	sel := usersTable.Select(usersTable.C("full_name"))
+
+	var name string
+	assert.Nil(t, engine.QueryRowContext(ctx, sel).Scan(&name))
+	assert.Equal(t, "Robert De Niro", name)
+
+	rows, err := engine.QueryContext(ctx, sel)
+	assert.Nil(t, err)
+	assert.True(t, rows.Next())
+	assert.Nil(t, rows.Close())
+
+	var user struct{ FullName string }
+	assert.Nil(t, engine.GetContext(ctx, sel, &user))
+	assert.Equal(t, "Robert De Niro", user.FullName)
+
+	var users []struct{ FullName string }
+	assert.Nil(t, engine.SelectContext(ctx, sel, &users))
+	assert.Equal(t, 1, len(users))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = engine.ExecContext(canceled, usersTable.Insert().
+		Values(map[string]interface{}{
+			"full_name": "Al Pacino",
+		}),
+	)
+	assert.NotNil(t, err)
+	assert.IsType(t, qb.Error{}, err)
+
+	_, err = engine.QueryContext(canceled, sel)
+	assert.NotNil(t, err)
+	assert.NotNil(t, engine.QueryRowContext(canceled, sel).Scan(&name))
+	assert.NotNil(t, engine.GetContext(canceled, sel, &user))
+	assert.NotNil(t, engine.SelectContext(canceled, sel, &users))
+
+	_, err = engine.BeginTx(canceled, nil)
+	assert.NotNil(t, err)
+}
+
+func TestTxContext(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+
+	usersTable := qb.Table(
+		"users",
+		qb.Column("full_name", qb.Varchar()).NotNull(),
+	)
+
+	_, err = engine.DB().Exec(usersTable.Create(engine.Dialect()))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+	sel := usersTable.Select(usersTable.C("full_name"))
+
+	tx, err := engine.BeginTx(ctx, &sql.TxOptions{})
+	assert.Nil(t, err)
+
+	_, err = tx.ExecContext(ctx, usersTable.Insert().
+		Values(map[string]interface{}{
+			"full_name": "Robert De Niro",
+		}),
+	)
+	assert.Nil(t, err)
+
+	var name string
+	assert.Nil(t, tx.QueryRowContext(ctx, sel).Scan(&name))
+	assert.Equal(t, "Robert De Niro", name)
+
+	rows, err := tx.QueryContext(ctx, sel)
+	assert.Nil(t, err)
+	assert.True(t, rows.Next())
+	assert.Nil(t, rows.Close())
+
+	var user struct{ FullName string }
+	assert.Nil(t, tx.GetContext(ctx, sel, &user))
+	assert.Equal(t, "Robert De Niro", user.FullName)
+
+	var users []struct{ FullName string }
+	assert.Nil(t, tx.SelectContext(ctx, sel, &users))
+	assert.Equal(t, 1, len(users))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = tx.ExecContext(canceled, usersTable.Insert().
+		Values(map[string]interface{}{
+			"full_name": "Al Pacino",
+		}),
+	)
+	assert.NotNil(t, err)
+	assert.IsType(t, qb.Error{}, err)
+
+	assert.Nil(t, tx.Commit())
+}
+
+func TestExecutor(t *testing.T) {
+	assert.Implements(t, (*qb.Executor)(nil), &qb.Engine{})
+	assert.Implements(t, (*qb.Executor)(nil), &qb.Tx{})
+}
+
+func TestEngineTransaction(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+
+	usersTable := qb.Table(
+		"users",
+		qb.Column("full_name", qb.Varchar()).NotNull(),
+	)
+
+	_, err = engine.DB().Exec(usersTable.Create(engine.Dialect()))
+	assert.Nil(t, err)
+
+	countStmt := qb.Select(qb.Count(usersTable.C("full_name"))).From(usersTable)
+	count := func() int {
+		var count int
+		assert.Nil(t, engine.QueryRow(countStmt).Scan(&count))
+		return count
+	}
+	insert := func(executor qb.Executor, name interface{}) error {
+		_, err := executor.Exec(usersTable.Insert().
+			Values(map[string]interface{}{
+				"full_name": name,
+			}),
+		)
+		return err
+	}
+
+	err = engine.Transaction(func(tx *qb.Tx) error {
+		return insert(tx, "Robert De Niro")
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count())
+
+	failure := errors.New("failure")
+	err = engine.Transaction(func(tx *qb.Tx) error {
+		assert.Nil(t, insert(tx, "Al Pacino"))
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, count())
+
+	assert.Panics(t, func() {
+		engine.Transaction(func(tx *qb.Tx) error {
+			assert.Nil(t, insert(tx, "Al Pacino"))
+			panic("failure")
+		})
+	})
+	assert.Equal(t, 1, count())
+
+	err = engine.Transaction(func(tx *qb.Tx) error {
+		return insert(tx, nil)
+	})
+	assert.IsType(t, qb.Error{}, err)
+	assert.Equal(t, 1, count())
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = engine.TransactionContext(canceled, nil, func(tx *qb.Tx) error {
+		return insert(tx, "Al Pacino")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, count())
+}
+
+func TestTxSavepoint(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+
+	usersTable := qb.Table(
+		"users",
+		qb.Column("full_name", qb.Varchar()).NotNull(),
+	)
+
+	_, err = engine.DB().Exec(usersTable.Create(engine.Dialect()))
+	assert.Nil(t, err)
+
+	countStmt := qb.Select(qb.Count(usersTable.C("full_name"))).From(usersTable)
+	count := func(executor qb.Executor) int {
+		var count int
+		assert.Nil(t, executor.QueryRow(countStmt).Scan(&count))
+		return count
+	}
+	insert := func(executor qb.Executor, name string) {
+		_, err := executor.Exec(usersTable.Insert().
+			Values(map[string]interface{}{
+				"full_name": name,
+			}),
+		)
+		assert.Nil(t, err)
+	}
+
+	tx, err := engine.Begin()
+	assert.Nil(t, err)
+
+	insert(tx, "Robert De Niro")
+	assert.Nil(t, tx.Savepoint("sp1"))
+	insert(tx, "Al Pacino")
+	assert.Equal(t, 2, count(tx))
+	assert.Nil(t, tx.RollbackTo("sp1"))
+	assert.Equal(t, 1, count(tx))
+	assert.Nil(t, tx.Release("sp1"))
+	assert.NotNil(t, tx.Release("sp1"))
+	assert.Nil(t, tx.Commit())
+	assert.Equal(t, 1, count(engine))
+
+	failure := errors.New("failure")
+	err = engine.Transaction(func(tx *qb.Tx) error {
+		insert(tx, "Al Pacino")
+
+		assert.Equal(t, failure, tx.Transaction(func(tx *qb.Tx) error {
+			insert(tx, "Marlon Brando")
+			return failure
+		}))
+		assert.Equal(t, 2, count(tx))
+
+		assert.Panics(t, func() {
+			tx.Transaction(func(tx *qb.Tx) error {
+				insert(tx, "Marlon Brando")
+				panic("failure")
+			})
+		})
+		assert.Equal(t, 2, count(tx))
+
+		return tx.Transaction(func(tx *qb.Tx) error {
+			insert(tx, "Jack Nicholson")
+			return tx.Transaction(func(tx *qb.Tx) error {
+				insert(tx, "Marlon Brando")
+				return nil
+			})
+		})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, count(engine))
+}
+
+func TestEngineInsertBatch(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()).NotNull(),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(users)
+	assert.Nil(t, metadata.CreateAll(engine))
+
+	count, err := engine.InsertBatch(qb.Insert(users), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// 1200 rows * 2 columns exceeds the 999 bind parameters limit of sqlite
+	ins := qb.Insert(users)
+	for i := 0; i < 1200; i++ {
+		ins = ins.Rows(map[string]interface{}{"id": i, "email": "al@pacino.com"})
+	}
+	count, err = engine.InsertBatch(ins, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1200), count)
+
+	var total int
+	assert.Nil(t, engine.QueryRow(qb.Select(qb.Count(users.C("id"))).From(users)).Scan(&total))
+	assert.Equal(t, 1200, total)
+
+	// a failing chunk rolls back the whole batch
+	ins = qb.Insert(users).Rows(
+		map[string]interface{}{"id": 5000, "email": "al@pacino.com"},
+		map[string]interface{}{"id": 5001, "email": "al@pacino.com"},
+		map[string]interface{}{"id": 0, "email": "al@pacino.com"},
+	)
+	_, err = engine.InsertBatch(ins, 2)
+	assert.NotNil(t, err)
+	assert.Nil(t, engine.QueryRow(qb.Select(qb.Count(users.C("id"))).From(users)).Scan(&total))
+	assert.Equal(t, 1200, total)
+}
@@ -1,5 +1,7 @@
 package qb
 
+import "fmt"
+
 // ErrorCode discriminates the types of errors that qb wraps, mainly the
 // constraint errors
 // The different kind of errors are based on the python dbapi errors
@@ -46,6 +48,23 @@ const (
 	ErrNotSupported
 )
 
+// Interface error codes are in bits 5 to 7 too
+const (
+	// ErrCompile is for errors that occur while building a statement, e.g. a
+	// join condition that cannot be guessed, an unknown column or an invalid
+	// table definition
+	ErrCompile ErrorCode = ErrInterface | (iota + 1<<5)
+	// ErrUnsupported is for statements that use a feature the dialect does
+	// not support, e.g. RETURNING or FULL OUTER JOIN on mysql. Unlike
+	// ErrNotSupported, it is reported by qb before reaching the database
+	ErrUnsupported
+)
+
+// CompileError returns an ErrCompile Error with a formatted message
+func CompileError(format string, args ...interface{}) Error {
+	return Error{Code: ErrCompile, Orig: fmt.Errorf(format, args...)}
+}
+
 // IsInterfaceError returns true if the error is a Interface error
 func (err ErrorCode) IsInterfaceError() bool {
 	return err&ErrInterface != 0
@@ -72,6 +91,10 @@ func (err Error) Error() string {
 		return "Uncategorized error: " + err.Orig.Error()
 	case ErrInterface:
 		return "Interface error: " + err.Orig.Error()
+	case ErrCompile:
+		return "Compile error: " + err.Orig.Error()
+	case ErrUnsupported:
+		return "Unsupported feature: " + err.Orig.Error()
 	case ErrDatabase:
 		return "Database error: " + err.Orig.Error()
 	case ErrData:
@@ -84,6 +107,8 @@ func (err Error) Error() string {
 		return "Database internal error: " + err.Orig.Error()
 	case ErrProgramming:
 		return "Database programming error: " + err.Orig.Error()
+	case ErrNotSupported:
+		return "Database feature not supported: " + err.Orig.Error()
 	default:
 		return err.Orig.Error()
 	}
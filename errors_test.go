@@ -14,12 +14,15 @@ func TestErrorMessages(t *testing.T) {
 	}{
 		{ErrAny, "Uncategorized error: xxx"},
 		{ErrInterface, "Interface error: xxx"},
+		{ErrCompile, "Compile error: xxx"},
+		{ErrUnsupported, "Unsupported feature: xxx"},
 		{ErrDatabase, "Database error: xxx"},
 		{ErrData, "Database data error: xxx"},
 		{ErrOperational, "Database operational error: xxx"},
 		{ErrIntegrity, "Database integrity error: xxx"},
 		{ErrInternal, "Database internal error: xxx"},
 		{ErrProgramming, "Database programming error: xxx"},
+		{ErrNotSupported, "Database feature not supported: xxx"},
 		{54, "xxx"},
 	}
 	for _, tt := range tests {
@@ -31,6 +34,12 @@ func TestErrorCode(t *testing.T) {
 	assert.True(t, ErrInterface.IsInterfaceError())
 	assert.False(t, ErrInterface.IsDatabaseError())
 
+	assert.True(t, ErrCompile.IsInterfaceError())
+	assert.False(t, ErrCompile.IsDatabaseError())
+
+	assert.True(t, ErrUnsupported.IsInterfaceError())
+	assert.False(t, ErrUnsupported.IsDatabaseError())
+
 	assert.True(t, ErrDatabase.IsDatabaseError())
 	assert.False(t, ErrDatabase.IsInterfaceError())
 
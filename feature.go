@@ -0,0 +1,123 @@
+package qb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Feature is a set of SQL features that are not supported by all the
+// dialects. Dialects return the features they support with Features(), and
+// the compiler reports an ErrUnsupported error when a statement uses a
+// feature that is missing
+type Feature uint
+
+const (
+	// FeatureReturning is the RETURNING clause of insert, update, upsert and
+	// delete statements
+	FeatureReturning Feature = 1 << iota
+	// FeatureForUpdate is the FOR UPDATE clause of select statements
+	FeatureForUpdate
+	// FeatureForUpdateOf is the FOR UPDATE OF <tables> clause of select statements
+	FeatureForUpdateOf
+	// FeatureRightJoin is RIGHT OUTER JOIN
+	FeatureRightJoin
+	// FeatureFullJoin is FULL OUTER JOIN
+	FeatureFullJoin
+	// FeatureWindow is window functions (OVER clauses and named windows)
+	FeatureWindow
+	// FeatureCTE is common table expressions (WITH and WITH RECURSIVE)
+	FeatureCTE
+	// FeatureUpdateFrom is multi-table updates (UPDATE ... FROM)
+	FeatureUpdateFrom
+	// FeatureILike is the case insensitive ILIKE operator
+	FeatureILike
+	// FeatureBoolean is a boolean type with TRUE and FALSE literals.
+	// Dialects without it store booleans as integers
+	FeatureBoolean
+	// FeatureAutoIncrement is auto-increment columns that are not the
+	// primary key of the table
+	FeatureAutoIncrement
+	// FeatureDropCascade is DROP TABLE ... CASCADE and DROP INDEX ... CASCADE,
+	// which drop the objects depending on the table or index
+	FeatureDropCascade
+	// FeaturePartialIndex is indexes of the rows matching a WHERE clause
+	FeaturePartialIndex
+	// FeatureExpressionIndex is indexes of expressions rather than columns
+	FeatureExpressionIndex
+	// FeatureIndexMethod is the method of indexes (USING btree, hash...)
+	FeatureIndexMethod
+	// FeatureConcurrentIndex is CREATE and DROP INDEX CONCURRENTLY, which do
+	// not lock the table writes
+	FeatureConcurrentIndex
+	// FeatureIntersect is the INTERSECT and EXCEPT compound selects
+	FeatureIntersect
+	// FeatureIntersectAll is the INTERSECT ALL and EXCEPT ALL compound
+	// selects, which keep the duplicate rows
+	FeatureIntersectAll
+	// FeatureIndexIfExists is CREATE INDEX IF NOT EXISTS and DROP INDEX IF
+	// EXISTS
+	FeatureIndexIfExists
+	// FeatureAlterConstraint is ALTER TABLE ... ADD CONSTRAINT and DROP
+	// CONSTRAINT, without rebuilding the table
+	FeatureAlterConstraint
+)
+
+// AllFeatures is the set of all the features
+const AllFeatures = FeatureReturning | FeatureForUpdate | FeatureForUpdateOf |
+	FeatureRightJoin | FeatureFullJoin | FeatureWindow | FeatureCTE |
+	FeatureUpdateFrom | FeatureILike | FeatureBoolean | FeatureAutoIncrement |
+	FeatureDropCascade | FeaturePartialIndex | FeatureExpressionIndex |
+	FeatureIndexMethod | FeatureConcurrentIndex | FeatureIntersect |
+	FeatureIntersectAll | FeatureIndexIfExists | FeatureAlterConstraint
+
+var featureNames = map[Feature]string{
+	FeatureReturning:       "RETURNING",
+	FeatureForUpdate:       "FOR UPDATE",
+	FeatureForUpdateOf:     "FOR UPDATE OF",
+	FeatureRightJoin:       "RIGHT OUTER JOIN",
+	FeatureFullJoin:        "FULL OUTER JOIN",
+	FeatureWindow:          "window functions",
+	FeatureCTE:             "common table expressions",
+	FeatureUpdateFrom:      "UPDATE ... FROM",
+	FeatureILike:           "ILIKE",
+	FeatureBoolean:         "boolean type",
+	FeatureAutoIncrement:   "AUTOINCREMENT on non primary key columns",
+	FeatureDropCascade:     "DROP ... CASCADE",
+	FeaturePartialIndex:    "partial indexes",
+	FeatureExpressionIndex: "expression indexes",
+	FeatureIndexMethod:     "index methods",
+	FeatureConcurrentIndex: "CONCURRENTLY indexes",
+	FeatureIntersect:       "INTERSECT/EXCEPT",
+	FeatureIntersectAll:    "INTERSECT ALL/EXCEPT ALL",
+	FeatureIndexIfExists:   "IF [NOT] EXISTS indexes",
+	FeatureAlterConstraint: "ALTER TABLE ... ADD/DROP CONSTRAINT",
+}
+
+// Has returns true if the set has all the given features
+func (f Feature) Has(features Feature) bool {
+	return f&features == features
+}
+
+// String returns the names of the features of the set
+func (f Feature) String() string {
+	var names []string
+	for feature := FeatureReturning; feature <= AllFeatures; feature <<= 1 {
+		if f.Has(feature) {
+			names = append(names, featureNames[feature])
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// NotSupportedError returns an ErrUnsupported error describing a feature
+// that the dialect does not support
+func NotSupportedError(dialect Dialect, feature Feature) Error {
+	name := dialect.Driver()
+	if name == "" {
+		name = "default"
+	}
+	return Error{
+		Code: ErrUnsupported,
+		Orig: fmt.Errorf("%s is not supported by the %s dialect", feature, name),
+	}
+}
@@ -0,0 +1,62 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type restrictedDialect struct {
+	Dialect
+	features Feature
+}
+
+func (d restrictedDialect) Features() Feature { return d.features }
+
+func (d restrictedDialect) GetCompiler() Compiler { return SQLCompiler{d} }
+
+func TestFeatures(t *testing.T) {
+	assert.True(t, AllFeatures.Has(FeatureReturning|FeatureCTE))
+	assert.False(t, FeatureReturning.Has(FeatureReturning|FeatureCTE))
+	assert.Equal(t, "RETURNING, common table expressions", (FeatureReturning | FeatureCTE).String())
+
+	err := NotSupportedError(NewDefaultDialect(), FeatureILike)
+	assert.Equal(t, ErrUnsupported, err.Code)
+	assert.Equal(t, "ILIKE is not supported by the default dialect", err.Orig.Error())
+}
+
+func TestCompilerFeatures(t *testing.T) {
+	users := Table("users", Column("id", Int()).PrimaryKey(), Column("email", Varchar()))
+	sessions := Table("sessions", Column("user_id", Int()))
+	dialect := restrictedDialect{NewDefaultDialect(), FeatureForUpdate}
+
+	var tests = []struct {
+		builder Builder
+		missing Feature
+	}{
+		{Select(users.C("id")).From(users).ForUpdate(), 0},
+		{Select(users.C("id")).From(users).ForUpdate(users), FeatureForUpdateOf},
+		{Select(users.C("id")).From(users).RightJoin(sessions, users.C("id").Eq(sessions.C("user_id"))), FeatureRightJoin},
+		{Select(users.C("id")).From(users).FullJoin(sessions, users.C("id").Eq(sessions.C("user_id"))), FeatureFullJoin},
+		{Select(RowNumber().Over(Window())).From(users), FeatureWindow},
+		{Select(users.C("id")).From(users).Window("w", Window()), FeatureWindow},
+		{Select(SQLText("*")).From(With("u", Select(users.C("id")).From(users))), 0},
+		{Select(SQLText("*")).With(With("u", Select(users.C("id")).From(users))), FeatureCTE},
+		{Update(users).Values(map[string]interface{}{"email": "a"}).From(sessions), FeatureUpdateFrom},
+		{Update(users).Values(map[string]interface{}{"email": "a"}).Returning(users.C("id")), FeatureReturning},
+		{Delete(users).Returning(users.C("id")), FeatureReturning},
+		{Insert(users).Values(map[string]interface{}{"email": "a"}).Returning(users.C("id")), FeatureReturning},
+		{Union(Select(users.C("id")).From(users), Select(sessions.C("user_id")).From(sessions)), 0},
+		{Except(Select(users.C("id")).From(users), Select(sessions.C("user_id")).From(sessions)), FeatureIntersect},
+		{Compound("INTERSECT ALL", Select(users.C("id")).From(users), Select(sessions.C("user_id")).From(sessions)), FeatureIntersectAll},
+	}
+	for _, tt := range tests {
+		err := tt.builder.Build(dialect).Err()
+		if tt.missing == 0 {
+			assert.Nil(t, err)
+			continue
+		}
+		assert.Equal(t, NotSupportedError(dialect, tt.missing), err)
+		assert.Nil(t, tt.builder.Build(NewDefaultDialect()).Err())
+	}
+}
@@ -2,6 +2,7 @@ package qb
 
 import (
 	"fmt"
+	"hash/fnv"
 	"strings"
 )
 
@@ -9,12 +10,21 @@ import (
 type CompositeIndex string
 
 // Index generates an index clause given table and columns as params
+// The index is named i_<table>_<columns> unless its Name is set
 func Index(table string, cols ...string) IndexElem {
-	return IndexElem{
+	index := IndexElem{
 		Table:   table,
-		Name:    fmt.Sprintf("i_%s", strings.Join(cols, "_")),
 		Columns: cols,
 	}
+	index.Name = index.defaultName()
+	return index
+}
+
+// UniqueIndex generates a unique index clause given table and columns as params
+func UniqueIndex(table string, cols ...string) IndexElem {
+	index := Index(table, cols...)
+	index.Unique = true
+	return index
 }
 
 // IndexElem is the definition of any index elements for a table
@@ -22,9 +32,161 @@ type IndexElem struct {
 	Table   string
 	Name    string
 	Columns []string
+	// Unique makes the index a UNIQUE index
+	Unique bool
+	// Descending are the columns indexed in descending order
+	Descending []string
+	// Expressions are indexed after the columns
+	Expressions []Clause
+	// Condition is the WHERE clause of a partial index
+	Condition Clause
+	// Method is the index method, such as btree, hash or gin
+	Method string
+	// IfNotExists makes the index creation skipped if it already exists
+	IfNotExists bool
+	// Concurrently makes the index created without locking the table writes
+	Concurrently bool
+}
+
+// defaultName returns the name generated for the index: i_<table>_<columns>,
+// followed by expr_<hash> if the index has expressions, the hash being
+// computed from the compiled expressions so that the indexes of different
+// expressions have different names
+func (i IndexElem) defaultName() string {
+	parts := []string{"i"}
+	if i.Table != "" {
+		parts = append(parts, i.Table)
+	}
+	parts = append(parts, i.Columns...)
+	if len(i.Expressions) > 0 {
+		hash := fnv.New32a()
+		for _, expr := range i.Expressions {
+			hash.Write([]byte(compileExpression(NewDefaultDialect(), i.Table, expr) + ";"))
+		}
+		parts = append(parts, fmt.Sprintf("expr_%08x", hash.Sum32()))
+	}
+	return strings.Join(parts, "_")
+}
+
+// table sets the table of the index. A generated name is generated again to
+// be prefixed by the table name
+func (i IndexElem) table(name string) IndexElem {
+	generated := i.Name == i.defaultName()
+	i.Table = name
+	if generated {
+		i.Name = i.defaultName()
+	}
+	return i
+}
+
+// Desc makes the given columns indexed in descending order
+// Index("sessions", "user_id", "created_at").Desc("created_at")
+func (i IndexElem) Desc(cols ...string) IndexElem {
+	i.Descending = append(append([]string{}, i.Descending...), cols...)
+	return i
+}
+
+// Expr appends expressions to the indexed columns
+// Index("users").Expr(Aggregate("LOWER", users.C("email")))
+func (i IndexElem) Expr(exprs ...Clause) IndexElem {
+	generated := i.Name == i.defaultName()
+	i.Expressions = append(append([]Clause{}, i.Expressions...), exprs...)
+	if generated {
+		i.Name = i.defaultName()
+	}
+	return i
+}
+
+// Where makes the index a partial index of the rows matching the clause
+func (i IndexElem) Where(clause Clause) IndexElem {
+	i.Condition = clause
+	return i
+}
+
+// Using sets the index method
+func (i IndexElem) Using(method string) IndexElem {
+	i.Method = method
+	return i
+}
+
+// isDesc returns true if the column is indexed in descending order
+func (i IndexElem) isDesc(col string) bool {
+	return hasName(i.Descending, col)
 }
 
 // String returns the index element as an sql clause
 func (i IndexElem) String(dialect Dialect) string {
-	return fmt.Sprintf("CREATE INDEX %s ON %s(%s);", dialect.Escape(i.Name), dialect.Escape(i.Table), strings.Join(dialect.EscapeAll(i.Columns), ", "))
+	return i.Create(dialect) + ";"
+}
+
+// Create generates create index syntax. The IfNotExists and Concurrently
+// options apply
+func (i IndexElem) Create(dialect Dialect, options ...DDLOption) string {
+	return i.build(dialect, options...).SQLClauses()[0]
+}
+
+// Build generates a statement out of the create index syntax
+func (i IndexElem) Build(dialect Dialect) *Stmt {
+	return i.build(dialect)
+}
+
+// build generates a statement out of the create index syntax, given the
+// options of the CREATE statement
+func (i IndexElem) build(dialect Dialect, options ...DDLOption) *Stmt {
+	i.IfNotExists = i.IfNotExists || hasDDLOption(options, IfNotExists)
+	i.Concurrently = i.Concurrently || hasDDLOption(options, Concurrently)
+	context := NewCompilerContext(dialect)
+	statement := Statement()
+	statement.AddSQLClause(i.Accept(context))
+	statement.AddError(context.Errors()...)
+	return statement
+}
+
+// Drop generates drop index syntax. The IfExists, Cascade and Concurrently
+// options apply
+func (i IndexElem) Drop(dialect Dialect, options ...DDLOption) string {
+	return DropIndex(i.Table, i.Name, options...).Build(dialect).SQL()
+}
+
+// Accept calls the compiler VisitIndex method
+func (i IndexElem) Accept(context Context) string {
+	return context.Compiler().VisitIndex(context, i)
+}
+
+// DropIndex generates a DROP INDEX statement given the table and the name of
+// the index. The IfExists, Cascade and Concurrently options apply
+func DropIndex(table string, name string, options ...DDLOption) DropIndexStmt {
+	return DropIndexStmt{
+		Table:        table,
+		Name:         name,
+		IfExists:     hasDDLOption(options, IfExists),
+		Cascade:      hasDDLOption(options, Cascade),
+		Concurrently: hasDDLOption(options, Concurrently),
+	}
+}
+
+// DropIndexStmt is the base struct for the drop index statements
+type DropIndexStmt struct {
+	Table string
+	Name  string
+	// IfExists makes the drop skipped if the index does not exist
+	IfExists bool
+	// Cascade makes the objects depending on the index dropped too
+	Cascade bool
+	// Concurrently makes the index dropped without locking the table
+	Concurrently bool
+}
+
+// Accept calls the compiler VisitDropIndex method
+func (s DropIndexStmt) Accept(context Context) string {
+	return context.Compiler().VisitDropIndex(context, s)
+}
+
+// Build generates a statement out of DropIndexStmt object
+func (s DropIndexStmt) Build(dialect Dialect) *Stmt {
+	context := NewCompilerContext(dialect)
+	statement := Statement()
+	statement.AddSQLClause(s.Accept(context))
+	statement.AddError(context.Errors()...)
+	return statement
 }
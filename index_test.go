@@ -0,0 +1,104 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex(t *testing.T) {
+	dialect := NewDefaultDialect()
+	users := Table("users", Column("email", Varchar()), Column("deleted", Boolean()))
+	email := users.C("email")
+	deleted := users.C("deleted")
+
+	var tests = []struct {
+		index    IndexElem
+		expected string
+	}{
+		{Index("users", "email"), "CREATE INDEX i_users_email ON users(email)"},
+		{UniqueIndex("users", "email"), "CREATE UNIQUE INDEX i_users_email ON users(email)"},
+		{Index("users", "team_id", "created_at").Desc("created_at"),
+			"CREATE INDEX i_users_team_id_created_at ON users(team_id, created_at DESC)"},
+		{Index("users").Expr(Aggregate("LOWER", email)), "CREATE INDEX i_users_expr_9283c2fe ON users((LOWER(email)))"},
+		{Index("users", "email").Where(deleted.Eq(false)),
+			"CREATE INDEX i_users_email ON users(email) WHERE deleted = FALSE"},
+		{Index("users", "tags").Using("gin"), "CREATE INDEX i_users_tags ON users USING gin(tags)"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.index.Create(dialect))
+	}
+
+	assert.Equal(t,
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS i_users_email ON users(email)",
+		Index("users", "email").Create(dialect, IfNotExists, Concurrently))
+	assert.Equal(t, "CREATE INDEX i_users_email ON users(email);", Index("users", "email").String(dialect))
+
+	assert.NotEqual(t,
+		Index("users").Expr(Aggregate("LOWER", email)).Name,
+		Index("users").Expr(Aggregate("UPPER", email)).Name)
+
+	named := Index("users", "email")
+	named.Name = "users_by_email"
+	assert.Equal(t, "users_by_email", named.Expr(Aggregate("LOWER", email)).Name)
+	assert.Equal(t, "i_users_email", Index("", "email").table("users").Name)
+	assert.Equal(t, "users_by_email", named.table("members").Name)
+}
+
+func TestIndexTable(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("email", Varchar()),
+		Index("", "email"),
+	)
+	teams := Table(
+		"teams",
+		Column("id", Int()).PrimaryKey(),
+		Column("email", Varchar()),
+		Index("", "email"),
+	)
+
+	assert.Equal(t, "i_users_email", users.Indices[0].Name)
+	assert.Equal(t, "i_teams_email", teams.Indices[0].Name)
+}
+
+func TestIndexFeatures(t *testing.T) {
+	users := Table("users", Column("email", Varchar()), Column("deleted", Boolean()))
+	dialect := restrictedDialect{NewDefaultDialect(), 0}
+
+	var tests = []struct {
+		builder Builder
+		missing Feature
+	}{
+		{Index("users", "email").Desc("email"), 0},
+		{Index("users", "email").Where(users.C("deleted").Eq(false)), FeaturePartialIndex},
+		{Index("users").Expr(Aggregate("LOWER", users.C("email"))), FeatureExpressionIndex},
+		{Index("users", "email").Using("hash"), FeatureIndexMethod},
+		{IndexElem{Table: "users", Name: "i_users_email", Columns: []string{"email"}, Concurrently: true}, FeatureConcurrentIndex},
+		{DropIndex("users", "i_users_email", Concurrently), FeatureConcurrentIndex},
+		{DropIndex("users", "i_users_email", Cascade), FeatureDropCascade},
+		{Table("users", Column("email", Varchar()), Index("users", "email").Using("hash")), FeatureIndexMethod},
+	}
+	for _, tt := range tests {
+		err := tt.builder.Build(dialect).Err()
+		if tt.missing == 0 {
+			assert.Nil(t, err)
+			continue
+		}
+		assert.Equal(t, NotSupportedError(dialect, tt.missing), err)
+		assert.Nil(t, tt.builder.Build(NewDefaultDialect()).Err())
+	}
+}
+
+func TestDropIndex(t *testing.T) {
+	dialect := NewDefaultDialect()
+
+	assert.Equal(t, "DROP INDEX i_users_email;", Index("users", "email").Drop(dialect))
+	assert.Equal(t,
+		"DROP INDEX CONCURRENTLY IF EXISTS i_users_email;",
+		DropIndex("users", "i_users_email", IfExists, Concurrently).Build(dialect).SQL())
+	assert.Equal(t,
+		CompileError("DROP INDEX CONCURRENTLY does not support CASCADE"),
+		DropIndex("users", "i_users_email", Cascade, Concurrently).Build(dialect).Err())
+}
@@ -1,11 +1,21 @@
 package qb
 
+import (
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/serenize/snaker"
+)
+
+// rowMapper maps the struct fields to column names the same way the engine does
+var rowMapper = reflectx.NewMapperFunc("db", snaker.CamelToSnake)
+
 // Insert generates an insert statement and returns it
 // Insert(usersTable).Values(map[string]interface{}{"id": 1})
 func Insert(table TableElem) InsertStmt {
 	return InsertStmt{
 		table:     table,
-		values:    map[string]interface{}{},
+		rows:      []map[string]interface{}{},
 		returning: []ColumnElem{},
 	}
 }
@@ -13,20 +23,91 @@ func Insert(table TableElem) InsertStmt {
 // InsertStmt is the base struct for any insert statements
 type InsertStmt struct {
 	table     TableElem
-	values    map[string]interface{}
+	rows      []map[string]interface{}
+	columns   []string
+	fromCols  []ColumnElem
+	from      Query
 	returning []ColumnElem
+	errors    []error
 }
 
 // Values accepts map[string]interface{} and forms the values map of insert statement
+// Successive calls are merged into the first row of the statement
+// The keys must be columns of the table, otherwise compiling the statement fails
 func (s InsertStmt) Values(values map[string]interface{}) InsertStmt {
+	s = s.firstRow()
 	for k, v := range values {
-		s.values[k] = v
+		s.rows[0][k] = v
+	}
+	return s
+}
+
+// OrderedValues sets values of the first row of the insert statement, and
+// renders the columns in the given order
+// Insert(usersTable).OrderedValues(Value("id", 1), Value("email", "al@pacino.com"))
+func (s InsertStmt) OrderedValues(values ...ColumnValue) InsertStmt {
+	s = s.firstRow()
+	for _, v := range values {
+		s.rows[0][v.Name] = v.Value
+		s.columns = append(s.columns, v.Name)
+	}
+	return s
+}
+
+// firstRow returns the statement with a copy of its first row, which can
+// then be modified without altering the statements it was derived from
+func (s InsertStmt) firstRow() InsertStmt {
+	rows := make([]map[string]interface{}, len(s.rows))
+	copy(rows, s.rows)
+	if len(rows) == 0 {
+		rows = append(rows, map[string]interface{}{})
+	} else {
+		rows[0] = copyValues(rows[0])
 	}
+	s.rows = rows
+	return s
+}
+
+// Rows appends rows to the insert statement, which then inserts all of them
+// in a single statement.
+// A row is either a map[string]interface{} or a struct (or a pointer to a
+// struct) whose fields are mapped to the table columns using the "db" tag.
+// Struct fields that are not columns of the table are ignored, and so are
+// auto-increment primary key fields having their zero value, so that the
+// database generates them.
+// All the rows must have the same columns
+// Insert(usersTable).Rows(User{ID: 1}, User{ID: 2})
+func (s InsertStmt) Rows(rows ...interface{}) InsertStmt {
+	for _, row := range rows {
+		values, err := rowValues(s.table, row)
+		if err != nil {
+			s.errors = append(s.errors, err)
+			continue
+		}
+		// the capacity is limited so that the rows of the statements derived
+		// from the same statement do not overwrite each other
+		s.rows = append(s.rows[:len(s.rows):len(s.rows)], values)
+	}
+	return s
+}
+
+// FromSelect makes the statement insert the rows returned by a select
+// statement (or a compound select) into the given columns, instead of the
+// values. If no column is given, the select must return all the columns of
+// the table in their definition order
+// Insert(archive).FromSelect([]ColumnElem{archive.C("id")}, Select(users.C("id")).From(users))
+func (s InsertStmt) FromSelect(cols []ColumnElem, query Query) InsertStmt {
+	s.fromCols = cols
+	s.from = query
 	return s
 }
 
 // Returning accepts the column names as strings and forms the returning array of insert statement
-// NOTE: Please use it in only postgres dialect, otherwise it'll crash
+// When the dialect does not support RETURNING, the engine emulates it by
+// selecting the inserted rows with their primary key values, in a transaction
+// for Engine.Get and Engine.Select, on a dedicated connection for
+// Engine.Query and Engine.QueryRow, and in the current transaction for the Tx
+// methods
 func (s InsertStmt) Returning(cols ...ColumnElem) InsertStmt {
 	for _, c := range cols {
 		s.returning = append(s.returning, c)
@@ -45,6 +126,107 @@ func (s InsertStmt) Build(dialect Dialect) *Stmt {
 	context := NewCompilerContext(dialect)
 	statement.AddSQLClause(s.Accept(context))
 	statement.AddBinding(context.Binds()...)
+	statement.AddError(context.Errors()...)
 
 	return statement
 }
+
+// chunks splits the insert statement in several statements having at most
+// maxBinds bind parameters, an estimated size of at most maxBytes bytes if
+// maxBytes is positive, and at most batchSize rows if batchSize is positive
+func (s InsertStmt) chunks(maxBinds int, maxBytes int, batchSize int) []InsertStmt {
+	if s.from != nil {
+		return []InsertStmt{s}
+	}
+	if len(s.rows) == 0 {
+		return nil
+	}
+
+	size := len(s.rows)
+	if cols := len(s.rows[0]); cols > 0 && maxBinds > 0 {
+		size = maxBinds / cols
+	}
+	if batchSize > 0 && batchSize < size {
+		size = batchSize
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	chunks := []InsertStmt{}
+	add := func(rows []map[string]interface{}) {
+		chunk := s
+		chunk.rows = rows
+		chunks = append(chunks, chunk)
+	}
+	start, bytes := 0, 0
+	for i, row := range s.rows {
+		rowBytes := rowSize(row)
+		if i > start && (i-start == size || (maxBytes > 0 && bytes+rowBytes > maxBytes)) {
+			add(s.rows[start:i])
+			start, bytes = i, 0
+		}
+		bytes += rowBytes
+	}
+	add(s.rows[start:])
+	return chunks
+}
+
+// rowSize estimates the size in bytes of the values of a row once sent to
+// the database, each value having a few bytes of placeholder and encoding
+// overhead
+func rowSize(row map[string]interface{}) int {
+	size := 0
+	for _, v := range row {
+		switch value := v.(type) {
+		case string:
+			size += len(value)
+		case []byte:
+			size += len(value)
+		default:
+			size += 8
+		}
+		size += 8
+	}
+	return size
+}
+
+// rowValues returns the values of a row given as a map or a struct
+func rowValues(table TableElem, row interface{}) (map[string]interface{}, error) {
+	if values, ok := row.(map[string]interface{}); ok {
+		return copyValues(values), nil
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(row))
+	if v.Kind() != reflect.Struct {
+		return nil, CompileError("Cannot insert a row of type %T", row)
+	}
+
+	values := map[string]interface{}{}
+	for name, field := range rowMapper.TypeMap(v.Type()).Names {
+		col, ok := table.Columns[name]
+		if !ok || field.Embedded {
+			continue
+		}
+		value := reflectx.FieldByIndexesReadOnly(v, field.Index)
+		if col.Options.AutoIncrement && col.Options.PrimaryKey && isZero(value) {
+			continue
+		}
+		values[name] = value.Interface()
+	}
+	return values, nil
+}
+
+// isZero returns true if the value is the zero value of its type
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// copyValues returns a copy of the values of a row
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		copied[k] = v
+	}
+	return copied
+}
@@ -44,3 +44,207 @@ func TestInsert(t *testing.T) {
 	assert.Contains(t, sql, "RETURNING id, email")
 	assert.Contains(t, binds, "9883cf81-3b56-4151-ae4e-3903c5bc436d", "al@pacino.com")
 }
+
+func TestInsertRows(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+
+	type User struct {
+		ID       int    `db:"id"`
+		Email    string `db:"email"`
+		FullName string
+	}
+
+	ins := Insert(users).Rows(
+		map[string]interface{}{"id": 1, "email": "al@pacino.com"},
+		User{ID: 2, Email: "robert@deniro.com", FullName: "Robert De Niro"},
+		&User{ID: 3, Email: "jack@nicholson.com"},
+	)
+
+	ctx := NewCompilerContext(NewDefaultDialect())
+	sql := ins.Accept(ctx)
+	binds := ctx.Binds()
+
+	assert.Contains(t, sql, "INSERT INTO users(")
+	assert.Contains(t, sql, "VALUES(?, ?), (?, ?), (?, ?)")
+	assert.Equal(t, 6, len(binds))
+	assert.Contains(t, binds, "robert@deniro.com")
+	assert.Contains(t, binds, 3)
+
+	assert.Equal(t,
+		CompileError("All the rows of an insert statement must have the same columns"),
+		Insert(users).Rows(
+			map[string]interface{}{"id": 1, "email": "al@pacino.com"},
+			map[string]interface{}{"id": 2},
+		).Build(NewDefaultDialect()).Err(),
+	)
+	assert.Equal(t,
+		CompileError("Missing value for column 'id' in insert statement row"),
+		Insert(users).Rows(
+			map[string]interface{}{"id": 1},
+			map[string]interface{}{"email": "al@pacino.com"},
+		).Build(NewDefaultDialect()).Err(),
+	)
+	assert.Equal(t,
+		CompileError("Cannot insert a row of type int"),
+		Insert(users).Rows(42).Build(NewDefaultDialect()).Err(),
+	)
+}
+
+func TestInsertRowsAutoIncrement(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey().AutoIncrement(),
+		Column("email", Varchar()),
+	)
+
+	type User struct {
+		ID    int    `db:"id"`
+		Email string `db:"email"`
+	}
+
+	ctx := NewCompilerContext(NewDefaultDialect())
+	sql := Insert(users).Rows(User{Email: "al@pacino.com"}, User{Email: "robert@deniro.com"}).Accept(ctx)
+	assert.Equal(t, "INSERT INTO users(email)\nVALUES(?), (?)", sql)
+	assert.Equal(t, []interface{}{"al@pacino.com", "robert@deniro.com"}, ctx.Binds())
+
+	ctx = NewCompilerContext(NewDefaultDialect())
+	Insert(users).Rows(User{ID: 3, Email: "al@pacino.com"}).Accept(ctx)
+	assert.Equal(t, []interface{}{3, "al@pacino.com"}, ctx.Binds())
+}
+
+func TestInsertRowsCopy(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+
+	row := map[string]interface{}{"id": 1, "email": "al@pacino.com"}
+	ins := Insert(users).Rows(row)
+	ins.Values(map[string]interface{}{"email": "robert@deniro.com"})
+	assert.Equal(t, "al@pacino.com", row["email"])
+
+	base := Insert(users).Values(map[string]interface{}{"id": 1})
+	base.Values(map[string]interface{}{"email": "robert@deniro.com"})
+	ctx := NewCompilerContext(NewDefaultDialect())
+	assert.Equal(t, "INSERT INTO users(id)\nVALUES(?)", base.Accept(ctx))
+}
+
+func TestInsertUnknownColumn(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("email", Varchar()),
+	)
+
+	stmt := Insert(users).
+		Values(map[string]interface{}{"id": 1, "emial": "al@pacino.com"}).
+		Build(NewDefaultDialect())
+	assert.Equal(t, CompileError("No such column 'emial' in table users"), stmt.Err())
+}
+
+func TestInsertChunks(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+
+	ins := Insert(users)
+	assert.Equal(t, 0, len(ins.chunks(999, 0, 0)))
+
+	for i := 0; i < 5; i++ {
+		ins = ins.Rows(map[string]interface{}{"id": i, "email": "al@pacino.com"})
+	}
+
+	chunks := ins.chunks(4, 0, 0)
+	assert.Equal(t, 3, len(chunks))
+	assert.Equal(t, 2, len(chunks[0].rows))
+	assert.Equal(t, 1, len(chunks[2].rows))
+	assert.Equal(t, 3, chunks[1].rows[1]["id"])
+
+	chunks = ins.chunks(999, 0, 3)
+	assert.Equal(t, 2, len(chunks))
+	assert.Equal(t, 3, len(chunks[0].rows))
+
+	chunks = ins.chunks(1, 0, 0)
+	assert.Equal(t, 5, len(chunks))
+
+	// each row is estimated to 8 + 13 + 2 * 8 = 37 bytes
+	chunks = ins.chunks(999, 80, 0)
+	assert.Equal(t, 3, len(chunks))
+	assert.Equal(t, 2, len(chunks[0].rows))
+	assert.Equal(t, 1, len(chunks[2].rows))
+
+	chunks = ins.chunks(999, 10, 0)
+	assert.Equal(t, 5, len(chunks))
+}
+
+func TestInsertColumnOrder(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("full_name", Varchar()),
+	)
+
+	for i := 0; i < 10; i++ {
+		ctx := NewCompilerContext(NewDefaultDialect())
+		sql := Insert(users).Values(map[string]interface{}{
+			"full_name": "Al Pacino",
+			"email":     "al@pacino.com",
+			"id":        1,
+		}).Accept(ctx)
+		assert.Equal(t, "INSERT INTO users(id, email, full_name)\nVALUES(?, ?, ?)", sql)
+		assert.Equal(t, []interface{}{1, "al@pacino.com", "Al Pacino"}, ctx.Binds())
+	}
+
+	ctx := NewCompilerContext(NewDefaultDialect())
+	sql := Insert(users).
+		OrderedValues(Value("full_name", "Al Pacino"), Value("id", 1)).
+		Values(map[string]interface{}{"email": "al@pacino.com"}).
+		Accept(ctx)
+	assert.Equal(t, "INSERT INTO users(full_name, id, email)\nVALUES(?, ?, ?)", sql)
+	assert.Equal(t, []interface{}{"Al Pacino", 1, "al@pacino.com"}, ctx.Binds())
+}
+
+func TestInsertFromSelect(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("active", Boolean()),
+	)
+	archive := Table(
+		"archived_users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+
+	sel := Select(users.C("id"), users.C("email")).
+		From(users).
+		Where(users.C("active").Eq(false))
+
+	ctx := NewCompilerContext(NewDefaultDialect())
+	sql := Insert(archive).
+		FromSelect([]ColumnElem{archive.C("id"), archive.C("email")}, sel).
+		Returning(archive.C("id")).
+		Accept(ctx)
+	assert.Equal(t, "INSERT INTO archived_users(id, email)\nSELECT id, email\nFROM users\nWHERE active = ?\nRETURNING id", sql)
+	assert.Equal(t, []interface{}{false}, ctx.Binds())
+
+	ctx = NewCompilerContext(NewDefaultDialect())
+	sql = Insert(archive).FromSelect(nil, Union(
+		Select(users.C("id"), users.C("email")).From(users).Where(users.C("id").Eq(1)),
+		Select(archive.C("id"), archive.C("email")).From(archive).Where(archive.C("id").Eq(2)),
+	)).Accept(ctx)
+	assert.Equal(t, "INSERT INTO archived_users\nSELECT id, email\nFROM users\nWHERE id = ?\nUNION\nSELECT id, email\nFROM archived_users\nWHERE id = ?", sql)
+	assert.Equal(t, []interface{}{1, 2}, ctx.Binds())
+
+	ins := Insert(archive).FromSelect(nil, sel)
+	assert.Equal(t, []InsertStmt{ins}, ins.chunks(999, 0, 1))
+}
@@ -1,10 +1,16 @@
 package qb
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 )
 
+// errConcurrentlyInTx is returned when CreateAll or DropAll is given the
+// Concurrently option, which cannot apply in a transaction
+var errConcurrentlyInTx = CompileError("The Concurrently option cannot apply to statements executed in a transaction")
+
 // MetaData creates a new MetaData object and returns it as a pointer
 func MetaData() *MetaDataElem {
 	return &MetaDataElem{
@@ -22,7 +28,9 @@ func (m *MetaDataElem) AddTable(table TableElem) {
 	m.tables = append(m.tables, table)
 }
 
-// Table returns the metadata registered table object. It returns nil if table is not found
+// Table returns the metadata registered table object
+// If the table is not found, statements using the returned table fail to
+// compile
 func (m *MetaDataElem) Table(name string) TableElem {
 	for _, t := range m.tables {
 		if t.Name == name {
@@ -30,7 +38,11 @@ func (m *MetaDataElem) Table(name string) TableElem {
 		}
 	}
 
-	panic(fmt.Errorf("Table %s not found", name))
+	return TableElem{
+		Name:    name,
+		Columns: map[string]ColumnElem{},
+		errors:  []error{CompileError("Table %s not found", name)},
+	}
 }
 
 // Tables returns the current tables slice
@@ -38,48 +50,207 @@ func (m *MetaDataElem) Tables() []TableElem {
 	return m.tables
 }
 
-// CreateAll creates all the tables added to metadata
-func (m *MetaDataElem) CreateAll(engine *Engine) error {
-	tx, err := engine.DB().Begin()
-	if err != nil {
-		return err
+// CreateAll creates all the tables added to metadata, in a transaction
+// The tables are created after the tables their foreign keys refer to. The
+// foreign keys that belong to a cycle of references are added once all the
+// tables are created, with ALTER TABLE statements, unless the dialect lacks
+// FeatureAlterConstraint: such foreign keys are then kept in the tables, as
+// sqlite accepts references to tables that do not exist yet.
+// Each index is created by a separate statement, after its table.
+// With the IfNotExists option, the existing tables and indexes are skipped,
+// and failing to add the foreign keys of the cycles, which most likely exist
+// already, is ignored. The Concurrently option is refused, as the tables are
+// created in a transaction
+func (m *MetaDataElem) CreateAll(engine *Engine, options ...DDLOption) error {
+	if len(m.tables) == 0 {
+		return errors.New("Metadata is empty. You need to register tables by calling db.AddTable(model{})")
 	}
-
-	for _, t := range m.tables {
-		_, err = tx.Exec(t.Create(engine.Dialect()))
-		if err != nil {
-			return err
-		}
+	if hasDDLOption(options, Concurrently) {
+		return errConcurrentlyInTx
 	}
 
-	err = tx.Commit()
+	tables, cycles := m.sortTables(engine.dialect)
+	ifNotExists := hasDDLOption(options, IfNotExists)
+	return engine.Transaction(func(tx *Tx) error {
+		for _, t := range tables {
+			create := t
+			create.Indices = nil
+			if _, err := tx.Exec(createTable{create, options}); err != nil {
+				return err
+			}
+			for _, index := range t.Indices {
+				if err := createIndex(tx, index, ifNotExists); err != nil {
+					return err
+				}
+			}
+		}
+		return execCycles(tx, cycles, ifNotExists)
+	})
+}
 
-	if len(m.tables) == 0 {
-		return errors.New("Metadata is empty. You need to register tables by calling db.AddTable(model{})")
+// createIndex creates an index of a table created by CreateAll
+// If the dialect lacks FeatureIndexIfExists and implements IndexInspector,
+// an existing index is skipped rather than created with IF NOT EXISTS
+func createIndex(tx *Tx, index IndexElem, ifNotExists bool) error {
+	dialect := tx.engine.dialect
+	inspector, ok := dialect.(IndexInspector)
+	if ifNotExists && ok && !dialect.Features().Has(FeatureIndexIfExists) {
+		exists, err := inspector.IndexExists(context.Background(), tx.tx, index.Table, index.Name)
+		if err != nil || exists {
+			return tx.engine.TranslateError(err)
+		}
+		ifNotExists = false
 	}
-
+	index.IfNotExists = ifNotExists
+	_, err := tx.Exec(index)
 	return err
 }
 
-// DropAll drops all the tables which is added to metadata
-func (m *MetaDataElem) DropAll(engine *Engine) error {
-	tx, err := engine.DB().Begin()
-	if err != nil {
-		return err
+// DropAll drops all the tables which is added to metadata, in a transaction
+// The tables are dropped before the tables their foreign keys refer to. The
+// foreign keys that belong to a cycle of references are dropped first.
+// With the IfExists option, the missing tables are skipped, and failing to
+// drop the foreign keys of the cycles is ignored. With the Cascade option,
+// the objects depending on the tables are dropped too. The Concurrently
+// option is refused, as the tables are dropped in a transaction
+func (m *MetaDataElem) DropAll(engine *Engine, options ...DDLOption) error {
+	if len(m.tables) == 0 {
+		return errors.New("Metadata is empty")
+	}
+	if hasDDLOption(options, Concurrently) {
+		return errConcurrentlyInTx
 	}
 
-	for i := len(m.tables) - 1; i >= 0; i-- {
-		drop := m.tables[i].Drop(engine.Dialect())
-		_, err = tx.Exec(drop)
-		if err != nil {
+	tables, cycles := m.sortTables(engine.dialect)
+	drops := []AlterTableStmt{}
+	if !hasDDLOption(options, Cascade) {
+		for _, alter := range cycles {
+			drop := AlterTable(alter.Altered())
+			for _, action := range alter.Actions {
+				drop = drop.DropConstraint(action.Constraint.(ForeignKeyConstraint).name)
+			}
+			drops = append(drops, drop)
+		}
+	}
+	return engine.Transaction(func(tx *Tx) error {
+		if err := execCycles(tx, drops, hasDDLOption(options, IfExists)); err != nil {
 			return err
 		}
+		for i := len(tables) - 1; i >= 0; i-- {
+			if _, err := tx.Exec(dropTable{tables[i], options}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// execCycles executes the statements adding or dropping the foreign keys of
+// the reference cycles. If ignoreErrors is set, each statement is executed
+// in a nested transaction and its failure is ignored
+func execCycles(tx *Tx, alters []AlterTableStmt, ignoreErrors bool) error {
+	for _, alter := range alters {
+		if !ignoreErrors {
+			if _, err := tx.Exec(alter); err != nil {
+				return err
+			}
+			continue
+		}
+		tx.Transaction(func(tx *Tx) error {
+			_, err := tx.Exec(alter)
+			return err
+		})
 	}
+	return nil
+}
 
-	err = tx.Commit()
+// sortTables returns the tables sorted so that each table comes after the
+// tables its foreign keys refer to, the tables being otherwise kept in the
+// order they were added. The foreign keys that belong to a cycle of
+// references are removed from the tables, and returned as ALTER TABLE
+// statements adding them. They are named fk_<table>_<columns> if they have
+// no name. If the dialect lacks FeatureAlterConstraint, they are kept in the
+// tables instead.
+func (m *MetaDataElem) sortTables(dialect Dialect) ([]TableElem, []AlterTableStmt) {
+	deferCycles := dialect.Features().Has(FeatureAlterConstraint)
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	byName := map[string]TableElem{}
+	for _, t := range m.tables {
+		byName[t.Name] = t
+	}
+	state := map[string]int{}
+	sorted := []TableElem{}
+	cyclic := map[string][]ForeignKeyConstraint{}
 
-	if len(m.tables) == 0 {
-		return errors.New("Metadata is empty")
+	var visit func(t TableElem)
+	visit = func(t TableElem) {
+		state[t.Name] = visiting
+		fkeys := []ForeignKeyConstraint{}
+		for _, fkey := range t.ForeignKeyConstraints.FKeys {
+			if ref, ok := byName[fkey.RefTable]; ok && ref.Name != t.Name {
+				switch state[ref.Name] {
+				case unvisited:
+					visit(ref)
+				case visiting:
+					if !deferCycles {
+						break
+					}
+					if fkey.name == "" {
+						fkey.name = fmt.Sprintf("fk_%s_%s", t.Name, strings.Join(fkey.Cols, "_"))
+					}
+					cyclic[t.Name] = append(cyclic[t.Name], fkey)
+					continue
+				}
+			}
+			fkeys = append(fkeys, fkey)
+		}
+		t.ForeignKeyConstraints.FKeys = fkeys
+		state[t.Name] = visited
+		sorted = append(sorted, t)
 	}
-	return err
+	for _, t := range m.tables {
+		if state[t.Name] == unvisited {
+			visit(t)
+		}
+	}
+
+	alters := []AlterTableStmt{}
+	for _, t := range sorted {
+		if len(cyclic[t.Name]) == 0 {
+			continue
+		}
+		alter := AlterTable(t)
+		for _, fkey := range cyclic[t.Name] {
+			alter = alter.AddConstraint(fkey)
+		}
+		alters = append(alters, alter)
+	}
+	return sorted, alters
+}
+
+// createTable is the builder of the CREATE TABLE statement executed by
+// CreateAll
+type createTable struct {
+	table   TableElem
+	options []DDLOption
+}
+
+// Build generates the CREATE TABLE statement
+func (c createTable) Build(dialect Dialect) *Stmt {
+	return c.table.buildCreate(dialect, c.options...)
+}
+
+// dropTable is the builder of the DROP TABLE statement executed by DropAll
+type dropTable struct {
+	table   TableElem
+	options []DDLOption
+}
+
+// Build generates the DROP TABLE statement
+func (d dropTable) Build(dialect Dialect) *Stmt {
+	return d.table.buildDrop(dialect, d.options...)
 }
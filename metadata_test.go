@@ -0,0 +1,76 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tableNames(tables []TableElem) []string {
+	names := []string{}
+	for _, t := range tables {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestMetaDataSortTables(t *testing.T) {
+	metadata := MetaData()
+	metadata.AddTable(Table(
+		"sessions",
+		Column("id", Int()).PrimaryKey(),
+		Column("user_id", Int()),
+		ForeignKey("user_id").References("users", "id"),
+	))
+	metadata.AddTable(Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("parent_id", Int()),
+		Column("account_id", Int()),
+		ForeignKey("parent_id").References("users", "id"),
+		ForeignKey("account_id").References("accounts", "id"),
+	))
+	metadata.AddTable(Table("accounts", Column("id", Int()).PrimaryKey()))
+	metadata.AddTable(Table("logs", Column("id", Int()).PrimaryKey()))
+
+	tables, cycles := metadata.sortTables(NewDefaultDialect())
+	assert.Equal(t, []string{"accounts", "users", "sessions", "logs"}, tableNames(tables))
+	assert.Empty(t, cycles)
+	// self references are kept
+	assert.Len(t, tables[1].ForeignKeyConstraints.FKeys, 2)
+}
+
+func TestMetaDataSortTablesCycle(t *testing.T) {
+	dialect := NewDefaultDialect()
+	metadata := MetaData()
+	metadata.AddTable(Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("team_id", Int()),
+		ForeignKey("team_id").References("teams", "id"),
+	))
+	metadata.AddTable(Table(
+		"teams",
+		Column("id", Int()).PrimaryKey(),
+		Column("owner_id", Int()),
+		ForeignKey("owner_id").References("users", "id"),
+	))
+
+	tables, cycles := metadata.sortTables(dialect)
+	assert.Equal(t, []string{"teams", "users"}, tableNames(tables))
+	assert.Empty(t, tables[0].ForeignKeyConstraints.FKeys)
+	assert.Len(t, tables[1].ForeignKeyConstraints.FKeys, 1)
+	if assert.Len(t, cycles, 1) {
+		assert.Equal(t,
+			"ALTER TABLE teams ADD CONSTRAINT fk_teams_owner_id FOREIGN KEY(owner_id) REFERENCES users(id);",
+			cycles[0].Build(dialect).SQL())
+	}
+	// the registered tables are left untouched
+	assert.Len(t, metadata.Table("teams").ForeignKeyConstraints.FKeys, 1)
+
+	// the cycles are kept in the tables if they cannot be altered
+	tables, cycles = metadata.sortTables(restrictedDialect{dialect, 0})
+	assert.Equal(t, []string{"teams", "users"}, tableNames(tables))
+	assert.Len(t, tables[0].ForeignKeyConstraints.FKeys, 1)
+	assert.Empty(t, cycles)
+}
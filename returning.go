@@ -0,0 +1,114 @@
+package qb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// emulatesReturning returns true if the builder is an insert statement having
+// RETURNING columns the dialect does not support
+func emulatesReturning(dialect Dialect, builder Builder) bool {
+	insert, ok := builder.(InsertStmt)
+	return ok && len(insert.returning) > 0 && !dialect.Features().Has(FeatureReturning)
+}
+
+// emulateReturning executes an insert statement having RETURNING columns when
+// the dialect does not support them, and returns a select statement fetching
+// the returned columns of the inserted rows.
+// The inserted rows are found with their primary key values, or with the last
+// insert id for a single row insert in a table having an auto-incremented
+// primary key.
+// Any other builder is returned as-is. The insert and the select statements
+// must run in the same transaction
+func emulateReturning(
+	ctx context.Context,
+	dialect Dialect,
+	exec func(context.Context, Builder) (sql.Result, error),
+	builder Builder,
+) (Builder, error) {
+	if !emulatesReturning(dialect, builder) {
+		return builder, nil
+	}
+	insert := builder.(InsertStmt)
+
+	pkCols := insert.table.PrimaryCols()
+	if insert.from != nil || len(pkCols) == 0 {
+		return nil, Error{
+			Code: ErrUnsupported,
+			Orig: errors.New("RETURNING can only be emulated for inserted values in a table having a primary key"),
+		}
+	}
+	for _, row := range insert.rows {
+		for _, col := range pkCols {
+			if _, ok := row[col.Name]; !ok && (len(pkCols) != 1 || len(insert.rows) != 1) {
+				return nil, Error{
+					Code: ErrUnsupported,
+					Orig: errors.New("RETURNING cannot be emulated without the primary key values, except for single row inserts"),
+				}
+			}
+		}
+	}
+
+	returning := insert.returning
+	insert.returning = nil
+	res, err := exec(ctx, insert)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []Clause{}
+	for _, row := range insert.rows {
+		conditions := []Clause{}
+		for _, col := range pkCols {
+			value, ok := row[col.Name]
+			if !ok {
+				id, err := res.LastInsertId()
+				if err != nil {
+					return nil, dialect.WrapError(err)
+				}
+				value = id
+			}
+			conditions = append(conditions, Eq(col, value))
+		}
+		rows = append(rows, And(conditions...))
+	}
+
+	cols := []Clause{}
+	for _, col := range returning {
+		cols = append(cols, col)
+	}
+	return Select(cols...).From(insert.table).Where(Or(rows...)), nil
+}
+
+// emulateReturningOnConn emulates the RETURNING columns of an insert
+// statement on a dedicated connection, the last insert id being bound to the
+// connection running the insert, and returns the select statement to query on
+// it. The connection must be closed once the returned rows are
+func (e *Engine) emulateReturningOnConn(ctx context.Context, builder Builder) (*sql.Conn, *Stmt, error) {
+	conn, err := e.db.DB.Conn(ctx)
+	if err != nil {
+		return nil, nil, e.TranslateError(err)
+	}
+	exec := func(ctx context.Context, builder Builder) (sql.Result, error) {
+		statement := builder.Build(e.dialect)
+		if err := statement.Err(); err != nil {
+			return nil, err
+		}
+		e.log(statement)
+		res, err := conn.ExecContext(ctx, statement.SQL(), statement.Bindings()...)
+		return res, e.TranslateError(err)
+	}
+	builder, err = emulateReturning(ctx, e.dialect, exec, builder)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	statement := builder.Build(e.dialect)
+	if err := statement.Err(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	e.log(statement)
+	return conn, statement, nil
+}
@@ -0,0 +1,38 @@
+package qb
+
+// Savepoint generates a SAVEPOINT statement
+func Savepoint(name string) SavepointStmt {
+	return SavepointStmt{Action: "SAVEPOINT", Name: name}
+}
+
+// RollbackToSavepoint generates a ROLLBACK TO SAVEPOINT statement
+func RollbackToSavepoint(name string) SavepointStmt {
+	return SavepointStmt{Action: "ROLLBACK TO SAVEPOINT", Name: name}
+}
+
+// ReleaseSavepoint generates a RELEASE SAVEPOINT statement
+func ReleaseSavepoint(name string) SavepointStmt {
+	return SavepointStmt{Action: "RELEASE SAVEPOINT", Name: name}
+}
+
+// SavepointStmt is the base struct for the savepoint statements
+type SavepointStmt struct {
+	Action string
+	Name   string
+}
+
+// Accept calls the compiler VisitSavepoint method
+func (s SavepointStmt) Accept(context Context) string {
+	return context.Compiler().VisitSavepoint(context, s)
+}
+
+// Build generates a statement out of SavepointStmt object
+func (s SavepointStmt) Build(dialect Dialect) *Stmt {
+	context := NewCompilerContext(dialect)
+	statement := Statement()
+	statement.AddSQLClause(s.Accept(context))
+	statement.AddBinding(context.Binds()...)
+	statement.AddError(context.Errors()...)
+
+	return statement
+}
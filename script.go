@@ -0,0 +1,119 @@
+package qb
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Conn is the common interface of *sql.Conn and *sql.Tx used to run the
+// statements of a script
+type Conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// ScriptPreparer is implemented by the dialects needing to prepare the
+// connection before running the statements of a builder generating several
+// sql statements, such as AlterTableStmt.
+// PrepareScript is called on the connection before the transaction running
+// the statements begins, or on the transaction itself if inTx is true.
+// check is called in the transaction once the statements are executed, and
+// makes it rolled back if it returns an error. restore is called on the
+// connection once the transaction is over
+type ScriptPreparer interface {
+	PrepareScript(ctx context.Context, conn Conn, inTx bool, builder Builder) (check func(tx Conn) error, restore func() error, err error)
+}
+
+// statementsBuilder is implemented by the builders generating several sql
+// statements, to be executed one by one
+type statementsBuilder interface {
+	Builder
+	Statements(dialect Dialect) ([]*Stmt, error)
+}
+
+// prepareScript calls the dialect ScriptPreparer if it implements it, and
+// returns no-op check and restore functions otherwise
+func prepareScript(ctx context.Context, dialect Dialect, conn Conn, inTx bool, builder Builder) (func(Conn) error, func() error, error) {
+	if preparer, ok := dialect.(ScriptPreparer); ok {
+		return preparer.PrepareScript(ctx, conn, inTx, builder)
+	}
+	return func(Conn) error { return nil }, func() error { return nil }, nil
+}
+
+// execScript executes the statements one by one and returns the result of
+// the last one
+func (e *Engine) execScript(ctx context.Context, conn Conn, statements []*Stmt) (sql.Result, error) {
+	var res sql.Result
+	for _, statement := range statements {
+		e.log(statement)
+		var err error
+		if res, err = conn.ExecContext(ctx, statement.SQL(), statement.Bindings()...); err != nil {
+			return nil, e.TranslateError(err)
+		}
+	}
+	return res, nil
+}
+
+// execStatements executes the statements of the builder in a transaction,
+// on a single connection prepared by the dialect
+func (e *Engine) execStatements(ctx context.Context, builder statementsBuilder) (res sql.Result, err error) {
+	statements, err := builder.Statements(e.dialect)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := e.db.DB.Conn(ctx)
+	if err != nil {
+		return nil, e.TranslateError(err)
+	}
+	defer conn.Close()
+
+	check, restore, err := prepareScript(ctx, e.dialect, conn, false, builder)
+	if err != nil {
+		return nil, e.TranslateError(err)
+	}
+	defer func() {
+		if restoreErr := restore(); err == nil && restoreErr != nil {
+			res, err = nil, e.TranslateError(restoreErr)
+		}
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, e.TranslateError(err)
+	}
+	res, err = e.execScript(ctx, tx, statements)
+	if err == nil {
+		err = e.TranslateError(check(tx))
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return res, e.TranslateError(tx.Commit())
+}
+
+// execStatements executes the statements of the builder in the transaction,
+// prepared by the dialect
+func (tx *Tx) execStatements(ctx context.Context, builder statementsBuilder) (res sql.Result, err error) {
+	statements, err := builder.Statements(tx.engine.dialect)
+	if err != nil {
+		return nil, err
+	}
+	check, restore, err := prepareScript(ctx, tx.engine.dialect, tx.tx, true, builder)
+	if err != nil {
+		return nil, tx.engine.TranslateError(err)
+	}
+	defer func() {
+		if restoreErr := restore(); err == nil && restoreErr != nil {
+			res, err = nil, tx.engine.TranslateError(restoreErr)
+		}
+	}()
+
+	if res, err = tx.engine.execScript(ctx, tx.tx, statements); err != nil {
+		return nil, err
+	}
+	if err = check(tx.tx); err != nil {
+		return nil, tx.engine.TranslateError(err)
+	}
+	return res, nil
+}
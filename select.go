@@ -1,7 +1,5 @@
 package qb
 
-import "fmt"
-
 // Selectable is any clause from which we can select columns and is suitable
 // as a FROM clause element
 type Selectable interface {
@@ -12,6 +10,24 @@ type Selectable interface {
 	DefaultName() string
 }
 
+// Query is any clause that compiles to a statement returning rows, like
+// select statements and compound selects
+type Query interface {
+	Clause
+	ColumnList() []ColumnElem
+}
+
+// isSubQuery returns true if the value is a select statement or a compound
+// select, which must be enclosed in parentheses when used in an expression
+func isSubQuery(value interface{}) bool {
+	switch value.(type) {
+	case SelectStmt, CompoundClause:
+		return true
+	default:
+		return false
+	}
+}
+
 // Select generates a select statement and returns it
 func Select(clauses ...Clause) SelectStmt {
 	return SelectStmt{
@@ -23,17 +39,31 @@ func Select(clauses ...Clause) SelectStmt {
 
 // SelectStmt is the base struct for building select statements
 type SelectStmt struct {
+	WithClause      *WithClause
 	SelectList      []Clause
 	FromClause      Selectable
 	GroupByClause   []ColumnElem
 	OrderByClause   *OrderByClause
 	HavingClause    []HavingClause
+	WindowClauses   []WindowClause
 	WhereClause     *WhereClause
 	ForUpdateClause *ForUpdateClause
 	OffsetValue     *int
 	LimitValue      *int
 }
 
+// With appends common table expressions to the WITH clause of the select
+// statement
+func (s SelectStmt) With(ctes ...CTEClause) SelectStmt {
+	with := WithClause{}
+	if s.WithClause != nil {
+		with.CTEs = append(with.CTEs, s.WithClause.CTEs...)
+	}
+	with.CTEs = append(with.CTEs, ctes...)
+	s.WithClause = &with
+	return s
+}
+
 // Select sets the selected columns
 func (s SelectStmt) Select(clauses ...Clause) SelectStmt {
 	s.SelectList = clauses
@@ -107,6 +137,14 @@ func (s SelectStmt) Having(aggregate AggregateClause, op string, value interface
 	return s
 }
 
+// Window appends a named window definition to the select statement
+// The window can then be referred to with WindowRef(name)
+func (s SelectStmt) Window(name string, window WindowClause) SelectStmt {
+	window.Name = name
+	s.WindowClauses = append(s.WindowClauses, window)
+	return s
+}
+
 // Limit sets the limit number of rows
 func (s SelectStmt) Limit(limit int) SelectStmt {
 	s.LimitValue = &limit
@@ -126,12 +164,65 @@ func (s SelectStmt) LimitOffset(limit, offset int) SelectStmt {
 	return s
 }
 
+// FullJoin appends a full outer join clause to select statement
+func (s SelectStmt) FullJoin(right Selectable, onClause ...Clause) SelectStmt {
+	return s.From(Join("FULL OUTER JOIN", s.FromClause, right, onClause...))
+}
+
 // ForUpdate adds a "FOR UPDATE" clause
 func (s SelectStmt) ForUpdate(tables ...TableElem) SelectStmt {
 	s.ForUpdateClause = &ForUpdateClause{tables}
 	return s
 }
 
+// All returns the columns found in the select list
+func (s SelectStmt) All() []Clause {
+	var clauses []Clause
+	for _, col := range s.ColumnList() {
+		clauses = append(clauses, col)
+	}
+	return clauses
+}
+
+// ColumnList returns the columns found in the select list
+// Labelled expressions are returned as columns named after the label.
+func (s SelectStmt) ColumnList() []ColumnElem {
+	var cols []ColumnElem
+	for _, clause := range s.SelectList {
+		switch c := clause.(type) {
+		case ColumnElem:
+			cols = append(cols, c)
+		case LabelledClause:
+			col := ColumnElem{Name: c.Name}
+			if labelled, ok := c.Clause.(ColumnElem); ok {
+				col.Type = labelled.Type
+			}
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// C returns the select list column with the given name
+// If the select list has no such column, a column with the given name is
+// returned anyway so that columns of raw select lists can be referred to.
+// The select statement should be aliased with Alias() before its columns
+// are used in an outer statement.
+func (s SelectStmt) C(name string) ColumnElem {
+	for _, col := range s.ColumnList() {
+		if col.Name == name {
+			return col
+		}
+	}
+	return ColumnElem{Name: name}
+}
+
+// DefaultName returns an empty string because select statements have no name
+// by default
+func (s SelectStmt) DefaultName() string {
+	return ""
+}
+
 // Accept calls the compiler VisitSelect method
 func (s SelectStmt) Accept(context Context) string {
 	return context.Compiler().VisitSelect(context, s)
@@ -143,6 +234,7 @@ func (s SelectStmt) Build(dialect Dialect) *Stmt {
 	statement := Statement()
 	statement.AddSQLClause(s.Accept(context))
 	statement.AddBinding(context.Binds()...)
+	statement.AddError(context.Errors()...)
 
 	return statement
 }
@@ -165,14 +257,16 @@ func getTable(sel Selectable) (TableElem, bool) {
 }
 
 // GuessJoinOnClause finds a join 'ON' clause between two tables
+// If there is not exactly one foreign key between the tables, an ErrorClause
+// is returned and compiling the join fails
 func GuessJoinOnClause(left Selectable, right Selectable) Clause {
 	leftTable, ok := getTable(left)
 	if !ok {
-		panic("left Selectable is not a Table: Cannot guess join onClause")
+		return ErrorClause{CompileError("left Selectable is not a Table: Cannot guess join onClause")}
 	}
 	rightTable, ok := getTable(right)
 	if !ok {
-		panic("right Selectable is not a Table: Cannot guess join onClause")
+		return ErrorClause{CompileError("right Selectable is not a Table: Cannot guess join onClause")}
 	}
 
 	var candidates []joinOnClauseCandidate
@@ -196,9 +290,9 @@ func GuessJoinOnClause(left Selectable, right Selectable) Clause {
 	}
 	switch len(candidates) {
 	case 0:
-		panic(fmt.Sprintf(
+		return ErrorClause{CompileError(
 			"No foreign keys found between %s and %s",
-			leftTable.Name, rightTable.Name))
+			leftTable.Name, rightTable.Name)}
 	case 1:
 		candidate := candidates[0]
 		var clauses []Clause
@@ -214,18 +308,18 @@ func GuessJoinOnClause(left Selectable, right Selectable) Clause {
 		}
 		return And(clauses...)
 	default:
-		panic(fmt.Sprintf(
+		return ErrorClause{CompileError(
 			"Found %d foreign keys between %s and %s",
-			len(candidates), leftTable.Name, rightTable.Name))
+			len(candidates), leftTable.Name, rightTable.Name)}
 	}
 }
 
 // MakeJoinOnClause assemble a 'ON' clause for a join from either:
 // 0 clause: attempt to guess the join clause (only if left & right are tables),
-//           otherwise panics
+//           otherwise returns an ErrorClause
 // 1 clause: returns it
 // 2 clauses: returns a Eq() of both
-// otherwise if panics
+// otherwise returns an ErrorClause
 func MakeJoinOnClause(left Selectable, right Selectable, onClause ...Clause) Clause {
 	switch len(onClause) {
 	case 0:
@@ -235,14 +329,14 @@ func MakeJoinOnClause(left Selectable, right Selectable, onClause ...Clause) Cla
 	case 2:
 		return Eq(onClause[0], onClause[1])
 	default:
-		panic("Cannot make a join condition with more than 2 clauses")
+		return ErrorClause{CompileError("Cannot make a join condition with more than 2 clauses")}
 	}
 }
 
 // Join returns a new JoinClause
 // onClause can be one of:
 // - 0 clause: attempt to guess the join clause (only if left & right are tables),
-//             otherwise panics
+//             otherwise compiling the join fails
 // - 1 clause: use it directly
 // - 2 clauses: use a Eq() of both
 func Join(joinType string, left Selectable, right Selectable, onClause ...Clause) JoinClause {
@@ -281,13 +375,14 @@ func (c JoinClause) ColumnList() []ColumnElem {
 // C returns the first column with the given name
 // If columns from both sides of the join match the name,
 // the one from the left side will be returned.
+// If no column matches, compiling the returned column fails
 func (c JoinClause) C(name string) ColumnElem {
 	for _, c := range c.ColumnList() {
 		if c.Name == name {
 			return c
 		}
 	}
-	panic(fmt.Sprintf("No such column '%s' in join %v", name, c))
+	return ColumnElem{Name: name, err: CompileError("No such column '%s' in join", name)}
 }
 
 // DefaultName returns an empty string because Joins have no name by default
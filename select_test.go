@@ -118,7 +118,10 @@ func (suite *SelectTestSuite) TestSelectInnerJoin() {
 	binds := suite.ctx.Binds()
 
 	assert.Equal(suite.T(), suite.sessions.C("user_id"), selInnerJoin.FromClause.C("user_id"))
-	assert.Panics(suite.T(), func() { selInnerJoin.FromClause.C("invalid") })
+	assert.Equal(suite.T(),
+		CompileError("No such column 'invalid' in join"),
+		Select(selInnerJoin.FromClause.C("invalid")).From(selInnerJoin.FromClause).Build(suite.dialect).Err(),
+	)
 	assert.Equal(suite.T(), len(suite.sessions.All())+len(suite.users.All()), len(selInnerJoin.FromClause.All()))
 
 	assert.Equal(suite.T(), "SELECT sessions.id, sessions.auth_token\nFROM sessions\nINNER JOIN users ON sessions.user_id = users.id\nWHERE sessions.user_id = ?", sql)
@@ -238,31 +241,277 @@ func (suite *SelectTestSuite) TestSelectGuessJoinOnClause() {
 		ForeignKey("c1", "c2").References("t1", "c1", "c2"),
 	)
 
-	assert.Panics(suite.T(), func() {
-		GuessJoinOnClause(t1, Alias("tt", t3))
-	})
+	assert.Equal(suite.T(),
+		ErrorClause{CompileError("right Selectable is not a Table: Cannot guess join onClause")},
+		GuessJoinOnClause(t1, Alias("tt", t3)))
 
-	assert.Panics(suite.T(), func() {
-		GuessJoinOnClause(Alias("tt", t3), t2)
-	})
+	assert.Equal(suite.T(),
+		ErrorClause{CompileError("left Selectable is not a Table: Cannot guess join onClause")},
+		GuessJoinOnClause(Alias("tt", t3), t2))
 
-	assert.Panics(suite.T(), func() {
-		GuessJoinOnClause(t1, &t2)
-	})
+	assert.Equal(suite.T(),
+		ErrorClause{CompileError("No foreign keys found between t1 and t2")},
+		GuessJoinOnClause(t1, &t2))
 
 	assert.Equal(suite.T(), "t3.c1 = t1.c1", GuessJoinOnClause(t3, t1).Accept(suite.ctx))
 	assert.Equal(suite.T(), "t3.c1 = t1.c1", GuessJoinOnClause(t1, t3).Accept(suite.ctx))
 	assert.Equal(suite.T(), "(t4.c1 = t1.c1 AND t4.c2 = t1.c2)", GuessJoinOnClause(t4, t1).Accept(suite.ctx))
 
-	assert.Panics(suite.T(), func() {
-		GuessJoinOnClause(t2, t3)
-	})
+	assert.Equal(suite.T(),
+		ErrorClause{CompileError("Found 2 foreign keys between t2 and t3")},
+		GuessJoinOnClause(t2, t3))
+
+	err := Select(t2.C("c1")).From(t2).InnerJoin(t3).Build(suite.dialect).Err()
+	assert.Equal(suite.T(), CompileError("Found 2 foreign keys between t2 and t3"), err)
 }
 
 func (suite *SelectTestSuite) TestSelectMakeJoinOnClause() {
-	assert.Panics(suite.T(), func() {
-		MakeJoinOnClause(TableElem{}, TableElem{}, And(), And(), And())
-	})
+	onClause := MakeJoinOnClause(TableElem{}, TableElem{}, And(), And(), And())
+	assert.Equal(suite.T(), "", onClause.Accept(suite.ctx))
+	assert.Equal(suite.T(),
+		[]error{CompileError("Cannot make a join condition with more than 2 clauses")},
+		suite.ctx.Errors())
+}
+
+func (suite *SelectTestSuite) TestSelectWith() {
+	active := With("active_users", Select(suite.users.C("id"), suite.users.C("email")).
+		From(suite.users).
+		Where(suite.users.C("password").NotEq("")))
+
+	sel := Select(active.C("email")).
+		With(active).
+		From(active).
+		Where(active.C("id").Gt(5))
+
+	sql := sel.Accept(suite.ctx)
+	expected := strings.Join([]string{
+		"WITH active_users AS (SELECT id, email",
+		"FROM users",
+		"WHERE password != ?)",
+		"SELECT email",
+		"FROM active_users",
+		"WHERE id > ?",
+	}, "\n")
+	assert.Equal(suite.T(), expected, sql)
+	assert.Equal(suite.T(), []interface{}{"", 5}, suite.ctx.Binds())
+
+	assert.Equal(suite.T(), "active_users", active.DefaultName())
+	assert.Equal(suite.T(), 2, len(active.All()))
+	assert.Equal(suite.T(), "active_users", active.C("email").Table)
+	assert.Equal(suite.T(), ColumnElem{Name: "unknown", Table: "active_users"}, active.C("unknown"))
+}
+
+func (suite *SelectTestSuite) TestSelectWithJoin() {
+	active := With("active_users", Select(suite.users.C("id")).From(suite.users))
+	tokens := With("tokens", Select(suite.sessions.C("user_id"), suite.sessions.C("auth_token")).From(suite.sessions))
+
+	sel := Select(tokens.C("auth_token")).
+		With(active).
+		With(tokens).
+		From(active).
+		InnerJoin(tokens, active.C("id"), tokens.C("user_id"))
+
+	sql := sel.Accept(suite.ctx)
+	expected := strings.Join([]string{
+		"WITH active_users AS (SELECT id",
+		"FROM users), tokens AS (SELECT user_id, auth_token",
+		"FROM sessions)",
+		"SELECT tokens.auth_token",
+		"FROM active_users",
+		"INNER JOIN tokens ON active_users.id = tokens.user_id",
+	}, "\n")
+	assert.Equal(suite.T(), expected, sql)
+}
+
+func (suite *SelectTestSuite) TestSelectWithRecursive() {
+	parents := WithRecursive("parents", Select(suite.sessions.C("id"), suite.sessions.C("user_id")).From(suite.sessions))
+	alias := Alias("p", parents)
+
+	sel := Select(alias.C("id")).With(parents).From(alias)
+
+	sql := sel.Accept(suite.ctx)
+	expected := strings.Join([]string{
+		"WITH RECURSIVE parents AS (SELECT id, user_id",
+		"FROM sessions)",
+		"SELECT id",
+		"FROM parents AS p",
+	}, "\n")
+	assert.Equal(suite.T(), expected, sql)
+}
+
+func (suite *SelectTestSuite) TestSelectWithRecursiveUnion() {
+	counter := Table("counter", Column("n", Int()))
+	cte := WithRecursive("counter", UnionAll(
+		Select(SQLText("1")),
+		Select(SQLText("n + 1")).From(counter).Where(counter.C("n").Lt(5)),
+	))
+
+	sel := Select(counter.C("n")).With(cte).From(cte)
+
+	sql := sel.Accept(suite.ctx)
+	expected := strings.Join([]string{
+		"WITH RECURSIVE counter AS (SELECT 1",
+		"UNION ALL",
+		"SELECT n + 1",
+		"FROM counter",
+		"WHERE n < ?)",
+		"SELECT n",
+		"FROM counter",
+	}, "\n")
+	assert.Equal(suite.T(), expected, sql)
+	assert.Equal(suite.T(), []interface{}{5}, suite.ctx.Binds())
+}
+
+func (suite *SelectTestSuite) TestSelectFromSubquery() {
+	counts := Alias("counts", Select(
+		suite.sessions.C("user_id"),
+		Count(suite.sessions.C("id")).Label("session_count"),
+	).From(suite.sessions).GroupBy(suite.sessions.C("user_id")))
+
+	assert.Equal(suite.T(), 2, len(counts.All()))
+	assert.Equal(suite.T(), ColumnElem{Name: "session_count", Table: "counts"}, counts.C("session_count"))
+	assert.Equal(suite.T(), "counts", counts.C("user_id").Table)
+	assert.Equal(suite.T(), BigInt(), counts.C("user_id").Type)
+
+	sel := Select(counts.C("user_id"), counts.C("session_count")).
+		From(counts).
+		Where(counts.C("session_count").Gt(2))
+
+	sql := sel.Accept(suite.ctx)
+	expected := strings.Join([]string{
+		"SELECT user_id, session_count",
+		"FROM (SELECT user_id, COUNT(id) AS session_count",
+		"FROM sessions",
+		"GROUP BY user_id) AS counts",
+		"WHERE session_count > ?",
+	}, "\n")
+	assert.Equal(suite.T(), expected, sql)
+	assert.Equal(suite.T(), []interface{}{2}, suite.ctx.Binds())
+}
+
+func (suite *SelectTestSuite) TestSelectJoinSubquery() {
+	counts := Alias("counts", Select(
+		suite.sessions.C("user_id"),
+		Label("session_count", Count(suite.sessions.C("id"))),
+	).From(suite.sessions).GroupBy(suite.sessions.C("user_id")))
+
+	sel := Select(suite.users.C("email"), counts.C("session_count")).
+		From(suite.users).
+		InnerJoin(counts, suite.users.C("id"), counts.C("user_id")).
+		Where(counts.C("session_count").Gt(2), suite.users.C("id").NotEq(1))
+
+	sql := sel.Accept(suite.ctx)
+	expected := strings.Join([]string{
+		"SELECT users.email, counts.session_count",
+		"FROM users",
+		"INNER JOIN (SELECT user_id, COUNT(id) AS session_count",
+		"FROM sessions",
+		"GROUP BY user_id) AS counts ON users.id = counts.user_id",
+		"WHERE (counts.session_count > ? AND users.id != ?)",
+	}, "\n")
+	assert.Equal(suite.T(), expected, sql)
+	assert.Equal(suite.T(), []interface{}{2, 1}, suite.ctx.Binds())
+}
+
+func (suite *SelectTestSuite) TestSelectUnaliasedSubquery() {
+	sub := Select(suite.sessions.C("user_id")).From(suite.sessions)
+	expected := CompileError("A subquery must be aliased to be selected from, use Alias()")
+
+	err := Select(sub.C("user_id")).From(sub).Build(suite.dialect).Err()
+	assert.Equal(suite.T(), expected, err)
+
+	err = Select(suite.users.C("email")).
+		From(suite.users).
+		InnerJoin(sub, suite.users.C("id"), sub.C("user_id")).
+		Build(suite.dialect).Err()
+	assert.Equal(suite.T(), expected, err)
+
+	err = Select(SQLText("*")).From(Union(sub, sub)).Build(suite.dialect).Err()
+	assert.Equal(suite.T(), expected, err)
+}
+
+func (suite *SelectTestSuite) TestSelectSubqueryColumns() {
+	sel := Select(suite.users.C("id"), suite.users.C("email").Label("login"), SQLText("1"))
+
+	assert.Equal(suite.T(), "", sel.DefaultName())
+	assert.Equal(suite.T(), 2, len(sel.All()))
+	assert.Equal(suite.T(), suite.users.C("id"), sel.C("id"))
+	assert.Equal(suite.T(), ColumnElem{Name: "login", Type: Varchar()}, sel.C("login"))
+	assert.Equal(suite.T(), ColumnElem{Name: "unknown"}, sel.C("unknown"))
+	assert.Equal(suite.T(), "users.email AS login", sel.SelectList[1].Accept(suite.ctx))
+}
+
+func (suite *SelectTestSuite) TestSelectScalarSubquery() {
+	sessionCount := Select(Count(suite.sessions.C("id"))).
+		From(suite.sessions).
+		Where(suite.sessions.C("user_id").Eq(suite.users.C("id")))
+
+	sel := Select(suite.users.C("email"), sessionCount).
+		From(suite.users).
+		Where(Gt(sessionCount, 2))
+
+	sql := sel.Accept(suite.ctx)
+	expected := strings.Join([]string{
+		"SELECT email, (SELECT COUNT(sessions.id)",
+		"FROM sessions",
+		"WHERE sessions.user_id = users.id)",
+		"FROM users",
+		"WHERE (SELECT COUNT(sessions.id)",
+		"FROM sessions",
+		"WHERE sessions.user_id = users.id) > ?",
+	}, "\n")
+	assert.Equal(suite.T(), expected, sql)
+	assert.Equal(suite.T(), []interface{}{2}, suite.ctx.Binds())
+}
+
+func (suite *SelectTestSuite) TestSelectInSubquery() {
+	active := Select(suite.sessions.C("user_id")).
+		From(suite.sessions).
+		Where(suite.sessions.C("auth_token").NotEq("expired"))
+
+	sql := Select(suite.users.C("id")).
+		From(suite.users).
+		Where(
+			suite.users.C("id").In(active),
+			suite.users.C("email").NotIn(Union(
+				Select(suite.users.C("email")).From(suite.users).Where(suite.users.C("id").Eq(1)),
+				Select(suite.users.C("email")).From(suite.users).Where(suite.users.C("id").Eq(2)),
+			)),
+		).
+		Accept(suite.ctx)
+	expected := strings.Join([]string{
+		"SELECT id",
+		"FROM users",
+		"WHERE (id IN (SELECT sessions.user_id",
+		"FROM sessions",
+		"WHERE sessions.auth_token != ?) AND email NOT IN (SELECT users.email",
+		"FROM users",
+		"WHERE users.id = ?",
+		"UNION",
+		"SELECT users.email",
+		"FROM users",
+		"WHERE users.id = ?))",
+	}, "\n")
+	assert.Equal(suite.T(), expected, sql)
+	assert.Equal(suite.T(), []interface{}{"expired", 1, 2}, suite.ctx.Binds())
+	assert.False(suite.T(), suite.ctx.InSubQuery())
+}
+
+func (suite *SelectTestSuite) TestSelectHavingSubquery() {
+	avg := Select(Avg(suite.users.C("id"))).From(suite.users)
+	sql := Select(suite.sessions.C("user_id")).
+		From(suite.sessions).
+		GroupBy(suite.sessions.C("user_id")).
+		Having(Count(suite.sessions.C("id")), ">", avg).
+		Accept(suite.ctx)
+	expected := strings.Join([]string{
+		"SELECT user_id",
+		"FROM sessions",
+		"GROUP BY user_id",
+		"HAVING COUNT(id) > (SELECT AVG(users.id)",
+		"FROM users)",
+	}, "\n")
+	assert.Equal(suite.T(), expected, sql)
 }
 
 func TestSelectTestSuite(t *testing.T) {
@@ -1,6 +1,7 @@
 package qb
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -15,27 +16,225 @@ type SQLCompiler struct {
 	Dialect Dialect
 }
 
+// subQuery compiles a select statement or a compound select used as a
+// subquery. Columns are qualified so that the subquery can refer to the
+// tables of the enclosing statement.
+func subQuery(context Context, query Clause) string {
+	inSubQuery := context.InSubQuery()
+	context.SetInSubQuery(true)
+	defer context.SetInSubQuery(inSubQuery)
+	return query.Accept(context)
+}
+
+// operand compiles a clause used as an operand of an expression
+// Subqueries are enclosed in parentheses.
+func operand(context Context, clause Clause) string {
+	if isSubQuery(clause) {
+		return "(" + subQuery(context, clause) + ")"
+	}
+	return clause.Accept(context)
+}
+
+// CheckFeature records an ErrUnsupported error in the context if the dialect
+// does not support the given feature, and returns whether it is supported
+func (c SQLCompiler) CheckFeature(context Context, feature Feature) bool {
+	if context.Dialect().Features().Has(feature) {
+		return true
+	}
+	context.AddError(NotSupportedError(context.Dialect(), feature))
+	return false
+}
+
 // VisitAggregate compiles aggregate functions (COUNT, SUM...)
 func (c SQLCompiler) VisitAggregate(context Context, aggregate AggregateClause) string {
+	if aggregate.clause == nil {
+		return aggregate.fn + "()"
+	}
 	return fmt.Sprintf("%s(%s)", aggregate.fn, aggregate.clause.Accept(context))
 }
 
 // VisitAlias compiles a '<selectable> AS <aliasname>' SQL clause
+// Select statements and compound selects are enclosed in parentheses, and
+// compiled as independent statements
 func (SQLCompiler) VisitAlias(context Context, alias AliasClause) string {
+	var sql string
+	switch alias.Selectable.(type) {
+	case SelectStmt, CompoundClause:
+		defaultTableName := context.DefaultTableName()
+		inSubQuery := context.InSubQuery()
+		context.SetInSubQuery(false)
+		sql = "(" + alias.Selectable.Accept(context) + ")"
+		context.SetDefaultTableName(defaultTableName)
+		context.SetInSubQuery(inSubQuery)
+	default:
+		sql = alias.Selectable.Accept(context)
+	}
 	return fmt.Sprintf(
 		"%s AS %s",
-		alias.Selectable.Accept(context),
+		sql,
 		context.Dialect().Escape(alias.Name),
 	)
 }
 
+// VisitAlterTable compiles an ALTER TABLE statement, the sql statements it
+// consists of being separated by semicolons
+func (c SQLCompiler) VisitAlterTable(context Context, alter AlterTableStmt) string {
+	return strings.Join(context.Compiler().CompileAlterTable(context, alter), ";\n")
+}
+
+// CompileAlterTable compiles an ALTER TABLE statement into the sql statements
+// to execute in order, the actions being combined by CombineAlterActions
+func (c SQLCompiler) CompileAlterTable(context Context, alter AlterTableStmt) []string {
+	return c.CombineAlterActions(context, alter, func(table TableElem, action AlterAction) string {
+		return c.CompileAlterClause(context, table, action)
+	})
+}
+
+// CombineAlterActions compiles the actions of an ALTER TABLE statement,
+// combining the consecutive ones in a single statement
+// ALTER TABLE users ADD COLUMN age INT, DROP COLUMN name
+// The renames, the index actions and the actions on a column that the
+// combined actions already alter are compiled as separate statements.
+// clause compiles an action given the table as altered by the previous ones
+func (c SQLCompiler) CombineAlterActions(context Context, alter AlterTableStmt, clause func(TableElem, AlterAction) string) []string {
+	escape := context.Dialect().Escape
+	statements := []string{}
+	var clauses, columns []string
+	flush := func(table TableElem) {
+		if len(clauses) > 0 {
+			statements = append(statements, "ALTER TABLE "+escape(table.Name)+" "+strings.Join(clauses, ", "))
+		}
+		clauses, columns = nil, nil
+	}
+
+	table := alter.Table
+	for _, action := range alter.Actions {
+		switch action.Op {
+		case AlterRenameColumn, AlterRenameTable:
+			flush(table)
+			statements = append(statements, "ALTER TABLE "+escape(table.Name)+" "+clause(table, action))
+		case AlterAddIndex, AlterDropIndex:
+			flush(table)
+			statements = append(statements, c.CompileAlterAction(context, table, action))
+		default:
+			if action.Name != "" && hasName(columns, action.Name) {
+				flush(table)
+			}
+			clauses = append(clauses, clause(table, action))
+			columns = append(columns, action.Name)
+		}
+		table = action.Apply(table)
+	}
+	flush(table)
+	return statements
+}
+
+// CompileAlterAction compiles an action of an ALTER TABLE statement as a
+// separate statement, given the table as altered by the previous actions
+func (c SQLCompiler) CompileAlterAction(context Context, table TableElem, action AlterAction) string {
+	switch action.Op {
+	case AlterAddIndex:
+		return action.Index.table(table.Name).Accept(context)
+	case AlterDropIndex:
+		return DropIndex(table.Name, action.Name).Accept(context)
+	}
+	return "ALTER TABLE " + context.Dialect().Escape(table.Name) + " " + c.CompileAlterClause(context, table, action)
+}
+
+// CompileAlterClause compiles the clause of an ALTER TABLE statement that
+// performs the action, given the table as altered by the previous actions
+// The index actions have no such clause and are compiled by
+// CompileAlterAction
+func (c SQLCompiler) CompileAlterClause(context Context, table TableElem, action AlterAction) string {
+	dialect := context.Dialect()
+	column := "ALTER COLUMN " + dialect.Escape(action.Name) + " "
+	switch action.Op {
+	case AlterAddColumn:
+		return "ADD COLUMN " + action.Column.String(dialect)
+	case AlterDropColumn:
+		return "DROP COLUMN " + dialect.Escape(action.Name)
+	case AlterRenameColumn:
+		return fmt.Sprintf("RENAME COLUMN %s TO %s", dialect.Escape(action.Name), dialect.Escape(action.NewName))
+	case AlterColumnType:
+		return column + "TYPE " + dialect.CompileType(action.Type)
+	case AlterSetNotNull:
+		return column + "SET NOT NULL"
+	case AlterDropNotNull:
+		return column + "DROP NOT NULL"
+	case AlterSetDefault:
+		return column + "SET " + Default(action.Default).String()
+	case AlterDropDefault:
+		return column + "DROP DEFAULT"
+	case AlterAddConstraint:
+		switch constraint := action.Constraint.(type) {
+		case UniqueKeyConstraint:
+			action.Constraint = constraint.Table(table.Name)
+		case CheckConstraint:
+			constraint.table = table.Name
+			action.Constraint = constraint
+		}
+		return "ADD " + strings.TrimSpace(action.Constraint.String(dialect))
+	case AlterDropConstraint:
+		return "DROP CONSTRAINT " + dialect.Escape(action.Name)
+	case AlterRenameTable:
+		return "RENAME TO " + dialect.Escape(action.NewName)
+	}
+	return ""
+}
+
+// arithmeticOperand compiles an operand of an arithmetic expression
+// Nested arithmetic expressions are enclosed in parentheses to keep the
+// evaluation order of the expression tree
+func arithmeticOperand(context Context, clause Clause) string {
+	switch clause.(type) {
+	case ArithmeticClause, UnaryClause:
+		return "(" + clause.Accept(context) + ")"
+	default:
+		return operand(context, clause)
+	}
+}
+
+// VisitArithmetic compiles LEFT <op> RIGHT arithmetic expressions
+func (c SQLCompiler) VisitArithmetic(context Context, arithmetic ArithmeticClause) string {
+	return fmt.Sprintf(
+		"%s %s %s",
+		arithmeticOperand(context, arithmetic.Left),
+		arithmetic.Op,
+		arithmeticOperand(context, arithmetic.Right),
+	)
+}
+
+// VisitBetween compiles a <left> (NOT) BETWEEN <low> AND <high> clause
+func (c SQLCompiler) VisitBetween(context Context, between BetweenClause) string {
+	op := "BETWEEN"
+	if between.Not {
+		op = "NOT BETWEEN"
+	}
+	return fmt.Sprintf(
+		"%s %s %s AND %s",
+		operand(context, between.Left),
+		op,
+		operand(context, between.Low),
+		operand(context, between.High),
+	)
+}
+
 // VisitBinary compiles LEFT <op> RIGHT expressions
+// ILIKE is compiled as LOWER(LEFT) LIKE LOWER(RIGHT) if the dialect does not
+// support it
 func (c SQLCompiler) VisitBinary(context Context, binary BinaryExpressionClause) string {
+	if binary.Op == "ILIKE" && !context.Dialect().Features().Has(FeatureILike) {
+		return fmt.Sprintf(
+			"LOWER(%s) LIKE LOWER(%s)",
+			operand(context, binary.Left),
+			operand(context, binary.Right),
+		)
+	}
 	return fmt.Sprintf(
 		"%s %s %s",
-		binary.Left.Accept(context),
+		operand(context, binary.Left),
 		binary.Op,
-		binary.Right.Accept(context),
+		operand(context, binary.Right),
 	)
 }
 
@@ -48,8 +247,11 @@ func (SQLCompiler) VisitBind(context Context, bind BindClause) string {
 // VisitColumn returns a column name, optionnaly escaped depending on the dialect
 // configuration
 func (c SQLCompiler) VisitColumn(context Context, column ColumnElem) string {
+	if column.err != nil {
+		context.AddError(column.err)
+	}
 	sql := ""
-	if context.InSubQuery() || context.DefaultTableName() != column.Table {
+	if column.Table != "" && (context.InSubQuery() || context.DefaultTableName() != column.Table) {
 		sql += c.Dialect.Escape(column.Table) + "."
 	}
 	sql += c.Dialect.Escape(column.Name)
@@ -67,26 +269,100 @@ func (c SQLCompiler) VisitCombiner(context Context, combiner CombinerClause) str
 	return fmt.Sprintf("(%s)", strings.Join(sqls, fmt.Sprintf(" %s ", combiner.operator)))
 }
 
+// compoundFeatures are the features required by the compound operators that
+// not all the dialects support
+var compoundFeatures = map[string]Feature{
+	"INTERSECT":     FeatureIntersect,
+	"EXCEPT":        FeatureIntersect,
+	"INTERSECT ALL": FeatureIntersectAll,
+	"EXCEPT ALL":    FeatureIntersectAll,
+}
+
+// VisitCompound compiles a compound select (UNION, INTERSECT...)
+// Each select statement is compiled as an independent statement, enclosed in
+// parentheses if it has its own ORDER BY, LIMIT or OFFSET clause.
+// An ErrUnsupported error is recorded if the dialect does not support the
+// operator
+func (c SQLCompiler) VisitCompound(context Context, compound CompoundClause) string {
+	if feature, ok := compoundFeatures[compound.Operator]; ok {
+		c.CheckFeature(context, feature)
+	}
+
+	defaultTableName := context.DefaultTableName()
+	inSubQuery := context.InSubQuery()
+	defer func() {
+		context.SetDefaultTableName(defaultTableName)
+		context.SetInSubQuery(inSubQuery)
+	}()
+
+	selects := []string{}
+	for _, sel := range compound.Selects {
+		sql := sel.Accept(context)
+		if sel.OrderByClause != nil || sel.LimitValue != nil || sel.OffsetValue != nil {
+			sql = "(" + sql + ")"
+		}
+		selects = append(selects, sql)
+	}
+	lines := []string{strings.Join(selects, "\n"+compound.Operator+"\n")}
+
+	context.SetDefaultTableName("")
+	if compound.OrderByClause != nil {
+		lines = append(lines, compound.OrderByClause.Accept(context))
+	}
+	if sql := c.LimitOffset(compound.LimitValue, compound.OffsetValue); sql != "" {
+		lines = append(lines, sql)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 // VisitDelete compiles a DELETE statement
+// The tables of a multi-table delete are rendered in a USING clause
 func (c SQLCompiler) VisitDelete(context Context, delete DeleteStmt) string {
-	sql := "DELETE FROM " + delete.table.Accept(context)
+	sql := "DELETE FROM " + delete.Table.Accept(context)
 
-	if delete.where != nil {
-		sql += "\n" + delete.where.Accept(context)
+	if len(delete.UsingTables) > 0 {
+		using := []string{}
+		for _, t := range delete.UsingTables {
+			using = append(using, t.Accept(context))
+		}
+		sql += "\nUSING " + strings.Join(using, ", ")
 	}
 
-	returning := []string{}
-	for _, c := range delete.returning {
-		returning = append(returning, context.Dialect().Escape(c.Name))
+	if delete.WhereClause != nil {
+		sql += "\n" + delete.WhereClause.Accept(context)
 	}
 
-	if len(returning) > 0 {
-		sql += "\nRETURNING " + strings.Join(returning, ", ")
-	}
+	sql += c.CompileReturning(context, delete.ReturningCols)
 
 	return sql
 }
 
+// VisitDropIndex compiles a DROP INDEX statement
+func (c SQLCompiler) VisitDropIndex(context Context, drop DropIndexStmt) string {
+	sql := "DROP INDEX "
+	if drop.Concurrently && c.CheckFeature(context, FeatureConcurrentIndex) {
+		sql += "CONCURRENTLY "
+	}
+	if drop.IfExists && c.CheckFeature(context, FeatureIndexIfExists) {
+		sql += "IF EXISTS "
+	}
+	sql += context.Dialect().Escape(drop.Name)
+	if drop.Cascade && c.CheckFeature(context, FeatureDropCascade) {
+		sql += " CASCADE"
+	}
+	if drop.Concurrently && drop.Cascade {
+		context.AddError(CompileError("DROP INDEX CONCURRENTLY does not support CASCADE"))
+	}
+	return sql
+}
+
+// VisitExcluded compiles a reference to a value proposed for insertion by an
+// upsert statement
+func (c SQLCompiler) VisitExcluded(context Context, excluded ExcludedClause) string {
+	return "EXCLUDED." + context.Dialect().Escape(excluded.Column.Name)
+}
+
 // VisitExists compile a EXISTS clause
 func (SQLCompiler) VisitExists(context Context, exists ExistsClause) string {
 	var sql string
@@ -94,15 +370,15 @@ func (SQLCompiler) VisitExists(context Context, exists ExistsClause) string {
 		sql = "NOT "
 	}
 	sql += "EXISTS(%s)"
-	context.SetInSubQuery(true)
-	defer func() { context.SetInSubQuery(false) }()
-	return fmt.Sprintf(sql, exists.Select.Accept(context))
+	return fmt.Sprintf(sql, subQuery(context, exists.Select))
 }
 
 // VisitForUpdate compiles a 'FOR UPDATE' clause
 func (c SQLCompiler) VisitForUpdate(context Context, forUpdate ForUpdateClause) string {
+	c.CheckFeature(context, FeatureForUpdate)
 	var sql = "FOR UPDATE"
 	if len(forUpdate.Tables) != 0 {
+		c.CheckFeature(context, FeatureForUpdateOf)
 		var tablenames []string
 		for _, table := range forUpdate.Tables {
 			tablenames = append(tablenames, table.Name)
@@ -115,54 +391,155 @@ func (c SQLCompiler) VisitForUpdate(context Context, forUpdate ForUpdateClause)
 // VisitHaving compiles a HAVING clause
 func (c SQLCompiler) VisitHaving(context Context, having HavingClause) string {
 	aggSQL := having.aggregate.Accept(context)
-	return fmt.Sprintf("HAVING %s %s %s", aggSQL, having.op, Bind(having.value).Accept(context))
+	return fmt.Sprintf("HAVING %s %s %s", aggSQL, having.op, operand(context, GetClauseFrom(having.value)))
 }
 
 // VisitIn compiles a <left> (NOT) IN (<right>)
+// <right> is either a list of values or a subquery
+// An empty list of values is compiled as an always false (IN) or always true
+// (NOT IN) condition
 func (c SQLCompiler) VisitIn(context Context, in InClause) string {
+	if list, ok := in.Right.(ListClause); ok && len(list.Clauses) == 0 {
+		if in.Op == "NOT IN" {
+			return "1 = 1"
+		}
+		return "1 = 0"
+	}
+	var right string
+	if isSubQuery(in.Right) {
+		right = subQuery(context, in.Right)
+	} else {
+		right = in.Right.Accept(context)
+	}
 	return fmt.Sprintf(
 		"%s %s (%s)",
-		in.Left.Accept(context),
+		operand(context, in.Left),
 		in.Op,
-		in.Right.Accept(context),
+		right,
 	)
 }
 
+// VisitIndex compiles a CREATE INDEX statement
+// The expressions and the condition of the index are compiled with their
+// values as literals
+func (c SQLCompiler) VisitIndex(context Context, index IndexElem) string {
+	dialect := context.Dialect()
+	sql := "CREATE "
+	if index.Unique {
+		sql += "UNIQUE "
+	}
+	sql += "INDEX "
+	if index.Concurrently && c.CheckFeature(context, FeatureConcurrentIndex) {
+		sql += "CONCURRENTLY "
+	}
+	if index.IfNotExists && c.CheckFeature(context, FeatureIndexIfExists) {
+		sql += "IF NOT EXISTS "
+	}
+	sql += fmt.Sprintf("%s ON %s", dialect.Escape(index.Name), dialect.Escape(index.Table))
+	if index.Method != "" && c.CheckFeature(context, FeatureIndexMethod) {
+		sql += " USING " + index.Method
+	}
+
+	keys := []string{}
+	for _, col := range index.Columns {
+		key := dialect.Escape(col)
+		if index.isDesc(col) {
+			key += " DESC"
+		}
+		keys = append(keys, key)
+	}
+	if len(index.Expressions) > 0 && c.CheckFeature(context, FeatureExpressionIndex) {
+		for _, expr := range index.Expressions {
+			keys = append(keys, fmt.Sprintf("(%s)", compileExpression(dialect, index.Table, expr)))
+		}
+	}
+	sql += fmt.Sprintf("(%s)", strings.Join(keys, ", "))
+
+	if index.Condition != nil && c.CheckFeature(context, FeaturePartialIndex) {
+		sql += " WHERE " + compileExpression(dialect, index.Table, index.Condition)
+	}
+	return sql
+}
+
 // VisitInsert compiles a INSERT statement
 func (c SQLCompiler) VisitInsert(context Context, insert InsertStmt) string {
+	for _, err := range insert.errors {
+		context.AddError(err)
+	}
 	context.SetDefaultTableName(insert.table.Name)
 	defer func() { context.SetDefaultTableName("") }()
 
-	cols := List()
-	values := List()
-	for k, v := range insert.values {
-		cols.Clauses = append(cols.Clauses, insert.table.C(k))
-		values.Clauses = append(values.Clauses, Bind(v))
+	var sql string
+	if insert.from != nil {
+		sql = "INSERT INTO " + insert.table.Accept(context)
+		if len(insert.fromCols) > 0 {
+			cols := List()
+			for _, col := range insert.fromCols {
+				cols.Clauses = append(cols.Clauses, col)
+			}
+			sql += fmt.Sprintf("(%s)", cols.Accept(context))
+		}
+		sql += "\n" + insert.from.Accept(context)
+		context.SetDefaultTableName(insert.table.Name)
+	} else {
+		sql = c.insertValues(context, insert)
 	}
 
-	sql := fmt.Sprintf(
-		"INSERT INTO %s(%s)\nVALUES(%s)",
-		insert.table.Accept(context),
-		cols.Accept(context),
-		values.Accept(context),
-	)
+	sql += c.CompileReturning(context, insert.returning)
 
-	returning := []string{}
-	for _, r := range insert.returning {
-		returning = append(returning, r.Accept(context))
+	return sql
+}
+
+// insertValues compiles the INSERT INTO ... VALUES ... part of an insert statement
+func (c SQLCompiler) insertValues(context Context, insert InsertStmt) string {
+	cols := List()
+	names := []string{}
+	if len(insert.rows) > 0 {
+		names = orderedNames(insert.table, insert.columns, insert.rows[0])
+		for _, name := range names {
+			cols.Clauses = append(cols.Clauses, insert.table.C(name))
+		}
 	}
-	if len(insert.returning) > 0 {
-		sql += fmt.Sprintf(
-			"\nRETURNING %s",
-			strings.Join(returning, ", "),
-		)
+
+	rows := []string{}
+	for _, row := range insert.rows {
+		if len(row) != len(names) {
+			context.AddError(CompileError("All the rows of an insert statement must have the same columns"))
+			return ""
+		}
+		values := List()
+		for _, name := range names {
+			v, ok := row[name]
+			if !ok {
+				context.AddError(CompileError("Missing value for column '%s' in insert statement row", name))
+				return ""
+			}
+			values.Clauses = append(values.Clauses, GetClauseFrom(v))
+		}
+		rows = append(rows, fmt.Sprintf("(%s)", values.Accept(context)))
+	}
+	if len(rows) == 0 {
+		rows = append(rows, "()")
 	}
 
-	return sql
+	return fmt.Sprintf(
+		"INSERT INTO %s(%s)\nVALUES%s",
+		insert.table.Accept(context),
+		cols.Accept(context),
+		strings.Join(rows, ", "),
+	)
 }
 
 // VisitJoin compiles a JOIN (ON) clause
 func (c SQLCompiler) VisitJoin(context Context, join JoinClause) string {
+	switch join.JoinType {
+	case "RIGHT OUTER JOIN":
+		c.CheckFeature(context, FeatureRightJoin)
+	case "FULL OUTER JOIN":
+		c.CheckFeature(context, FeatureFullJoin)
+	}
+	checkAliased(context, join.Left)
+	checkAliased(context, join.Right)
 	sql := fmt.Sprintf(
 		"%s\n%s %s",
 		join.Left.Accept(context),
@@ -176,11 +553,29 @@ func (c SQLCompiler) VisitJoin(context Context, join JoinClause) string {
 	return sql
 }
 
+// checkAliased records an error if a subquery is selected from without
+// being aliased, which no dialect allows
+func checkAliased(context Context, selectable Selectable) {
+	switch selectable.(type) {
+	case SelectStmt, CompoundClause:
+		context.AddError(CompileError("A subquery must be aliased to be selected from, use Alias()"))
+	}
+}
+
 // VisitLabel returns a single label, optionally escaped
 func (c SQLCompiler) VisitLabel(context Context, label string) string {
 	return c.Dialect.Escape(label)
 }
 
+// VisitLabelled compiles a '<clause> AS <label>' SQL clause
+func (c SQLCompiler) VisitLabelled(context Context, labelled LabelledClause) string {
+	return fmt.Sprintf(
+		"%s AS %s",
+		labelled.Clause.Accept(context),
+		context.Compiler().VisitLabel(context, labelled.Name),
+	)
+}
+
 // VisitList compiles a list of values
 func (c SQLCompiler) VisitList(context Context, list ListClause) string {
 	var clauses []string
@@ -190,6 +585,15 @@ func (c SQLCompiler) VisitList(context Context, list ListClause) string {
 	return strings.Join(clauses, ", ")
 }
 
+// VisitNot compiles a NOT (<clause>) clause
+// AND and OR clauses are already enclosed in parentheses
+func (c SQLCompiler) VisitNot(context Context, not NotClause) string {
+	if _, ok := not.Clause.(CombinerClause); ok {
+		return "NOT " + not.Clause.Accept(context)
+	}
+	return fmt.Sprintf("NOT (%s)", not.Clause.Accept(context))
+}
+
 // VisitOrderBy compiles a ORDER BY sql clause
 func (c SQLCompiler) VisitOrderBy(context Context, OrderByClause OrderByClause) string {
 	cols := []string{}
@@ -200,12 +604,36 @@ func (c SQLCompiler) VisitOrderBy(context Context, OrderByClause OrderByClause)
 	return fmt.Sprintf("ORDER BY %s %s", strings.Join(cols, ", "), OrderByClause.t)
 }
 
+// VisitOver compiles a '<function> OVER <window>' window function call
+func (c SQLCompiler) VisitOver(context Context, over OverClause) string {
+	c.CheckFeature(context, FeatureWindow)
+	fn := over.Function.Accept(context)
+	if over.Window.isRef() {
+		return fmt.Sprintf("%s OVER %s", fn, context.Compiler().VisitLabel(context, over.Window.Ref))
+	}
+	return fmt.Sprintf("%s OVER (%s)", fn, over.Window.Accept(context))
+}
+
+// VisitQuantified compiles a ANY, ALL or SOME clause
+func (c SQLCompiler) VisitQuantified(context Context, quantified QuantifiedClause) string {
+	return fmt.Sprintf("%s (%s)", quantified.Quantifier, subQuery(context, quantified.Query))
+}
+
+// VisitSavepoint compiles a SAVEPOINT, ROLLBACK TO SAVEPOINT or
+// RELEASE SAVEPOINT statement
+func (c SQLCompiler) VisitSavepoint(context Context, savepoint SavepointStmt) string {
+	return savepoint.Action + " " + context.Compiler().VisitLabel(context, savepoint.Name)
+}
+
 // VisitSelect compiles a SELECT statement
 func (c SQLCompiler) VisitSelect(context Context, selectStmt SelectStmt) string {
 	lines := []string{}
 	addLine := func(s string) {
 		lines = append(lines, s)
 	}
+	if selectStmt.WithClause != nil {
+		addLine(selectStmt.WithClause.Accept(context))
+	}
 	if !context.InSubQuery() && selectStmt.FromClause != nil {
 		// context.DefaultTableName = selectStmt.FromClause.DefaultName()
 		context.SetDefaultTableName(selectStmt.FromClause.DefaultName())
@@ -214,13 +642,14 @@ func (c SQLCompiler) VisitSelect(context Context, selectStmt SelectStmt) string
 	// select
 	columns := []string{}
 	for _, c := range selectStmt.SelectList {
-		sql := c.Accept(context)
+		sql := operand(context, c)
 		columns = append(columns, sql)
 	}
 	addLine(fmt.Sprintf("SELECT %s", strings.Join(columns, ", ")))
 
 	// from
 	if selectStmt.FromClause != nil {
+		checkAliased(context, selectStmt.FromClause)
 		addLine(fmt.Sprintf("FROM %s", selectStmt.FromClause.Accept(context)))
 	}
 
@@ -244,21 +673,28 @@ func (c SQLCompiler) VisitSelect(context Context, selectStmt SelectStmt) string
 		addLine(sql)
 	}
 
+	// window
+	windows := []string{}
+	for _, w := range selectStmt.WindowClauses {
+		windows = append(windows, fmt.Sprintf(
+			"%s AS (%s)",
+			context.Compiler().VisitLabel(context, w.Name),
+			w.Accept(context),
+		))
+	}
+	if len(windows) > 0 {
+		c.CheckFeature(context, FeatureWindow)
+		addLine(fmt.Sprintf("WINDOW %s", strings.Join(windows, ", ")))
+	}
+
 	// order by
 	if selectStmt.OrderByClause != nil {
 		sql := selectStmt.OrderByClause.Accept(context)
 		addLine(sql)
 	}
 
-	if (selectStmt.OffsetValue != nil) || (selectStmt.LimitValue != nil) {
-		var tokens []string
-		if selectStmt.LimitValue != nil {
-			tokens = append(tokens, fmt.Sprintf("LIMIT %d", *selectStmt.LimitValue))
-		}
-		if selectStmt.OffsetValue != nil {
-			tokens = append(tokens, fmt.Sprintf("OFFSET %d", *selectStmt.OffsetValue))
-		}
-		addLine(strings.Join(tokens, " "))
+	if sql := c.LimitOffset(selectStmt.LimitValue, selectStmt.OffsetValue); sql != "" {
+		addLine(sql)
 	}
 
 	if selectStmt.ForUpdateClause != nil {
@@ -268,8 +704,24 @@ func (c SQLCompiler) VisitSelect(context Context, selectStmt SelectStmt) string
 	return strings.Join(lines, "\n")
 }
 
+// LimitOffset compiles the LIMIT and OFFSET clauses, if any
+func (c SQLCompiler) LimitOffset(limit *int, offset *int) string {
+	var tokens []string
+	if limit != nil {
+		tokens = append(tokens, fmt.Sprintf("LIMIT %d", *limit))
+	}
+	if offset != nil {
+		tokens = append(tokens, fmt.Sprintf("OFFSET %d", *offset))
+	}
+	return strings.Join(tokens, " ")
+}
+
 // VisitTable returns a table name, optionally escaped
+// The errors of the table definition are recorded in the context
 func (SQLCompiler) VisitTable(context Context, table TableElem) string {
+	for _, err := range table.errors {
+		context.AddError(err)
+	}
 	return context.Compiler().VisitLabel(context, table.Name)
 }
 
@@ -278,47 +730,164 @@ func (SQLCompiler) VisitText(context Context, text TextClause) string {
 	return text.Text
 }
 
+// VisitUnary compiles <op><clause> unary expressions
+func (c SQLCompiler) VisitUnary(context Context, unary UnaryClause) string {
+	return unary.Op + arithmeticOperand(context, unary.Clause)
+}
+
 // VisitUpdate compiles a UPDATE statement
+// The tables of a multi-table update are rendered in a FROM clause, and the
+// columns of the where clause are then qualified
 func (c SQLCompiler) VisitUpdate(context Context, update UpdateStmt) string {
-	context.SetDefaultTableName(update.table.Name)
+	context.SetDefaultTableName(update.Table.Name)
 	defer func() { context.SetDefaultTableName("") }()
 
-	sql := "UPDATE " + update.table.Accept(context)
+	sql := "UPDATE " + update.Table.Accept(context)
 
 	sets := List()
 
-	for k, v := range update.values {
+	for _, k := range update.ColumnNames() {
 		sets.Clauses = append(sets.Clauses,
-			Eq(update.table.C(k), Bind(v)))
+			BinaryExpression(update.Table.C(k), "=", GetClauseFrom(update.ValuesMap[k])))
 	}
 
 	if len(sets.Clauses) > 0 {
 		sql += "\nSET " + sets.Accept(context)
 	}
 
-	if update.where != nil {
-		sql += "\n" + update.where.Accept(context)
+	if len(update.FromTables) > 0 {
+		c.CheckFeature(context, FeatureUpdateFrom)
+		context.SetDefaultTableName("")
+		from := []string{}
+		for _, t := range update.FromTables {
+			from = append(from, t.Accept(context))
+		}
+		sql += "\nFROM " + strings.Join(from, ", ")
 	}
 
-	returning := []string{}
-	for _, c := range update.returning {
-		returning = append(returning, context.Dialect().Escape(c.Name))
+	if update.WhereClause != nil {
+		sql += "\n" + update.WhereClause.Accept(context)
 	}
 
-	if len(returning) > 0 {
-		sql += "\nRETURNING " + strings.Join(returning, ", ")
-	}
+	sql += c.CompileReturning(context, update.ReturningCols)
 
 	return sql
 }
 
-// VisitUpsert is not implemented and will panic.
+// VisitUpsert is not implemented and records an ErrUnsupported error.
 // It should be implemented in each dialect
 func (c SQLCompiler) VisitUpsert(context Context, upsert UpsertStmt) string {
-	panic("Upsert is not Implemented in this compiler")
+	context.AddError(Error{
+		Code: ErrUnsupported,
+		Orig: errors.New("Upsert is not implemented in this compiler"),
+	})
+	return ""
+}
+
+// CompileReturning compiles the RETURNING clause of insert, update, delete and
+// upsert statements, preceded by a newline
+// An ErrUnsupported error is recorded if the dialect does not support it
+func (c SQLCompiler) CompileReturning(context Context, cols []ColumnElem) string {
+	if len(cols) == 0 || !c.CheckFeature(context, FeatureReturning) {
+		return ""
+	}
+	returning := []string{}
+	for _, col := range cols {
+		returning = append(returning, context.Dialect().Escape(col.Name))
+	}
+	return "\nRETURNING " + strings.Join(returning, ", ")
+}
+
+// CompileOnConflict compiles the ON CONFLICT ... DO UPDATE/NOTHING clause of
+// an upsert statement, for the dialects implementing it
+func (c SQLCompiler) CompileOnConflict(context Context, upsert UpsertStmt) string {
+	sql := "ON CONFLICT"
+	if upsert.ConflictConstraint != "" {
+		sql += " ON CONSTRAINT " + context.Dialect().Escape(upsert.ConflictConstraint)
+	} else if !upsert.IgnoreConflict || len(upsert.ConflictCols) > 0 {
+		target := []string{}
+		for _, col := range upsert.ConflictTarget() {
+			target = append(target, context.Dialect().Escape(col.Name))
+		}
+		sql += fmt.Sprintf(" (%s)", strings.Join(target, ", "))
+		if upsert.ConflictWhere != nil {
+			sql += " " + upsert.ConflictWhere.Accept(context)
+		}
+	}
+
+	if upsert.IgnoreConflict {
+		return sql + " DO NOTHING"
+	}
+
+	// the columns of the values and of the where clause are qualified, as
+	// they are otherwise ambiguous with the excluded ones
+	defaultTableName := context.DefaultTableName()
+	defer context.SetDefaultTableName(defaultTableName)
+	updates := []string{}
+	for _, v := range upsert.UpdateSet() {
+		context.SetDefaultTableName(upsert.Table.Name)
+		target := upsert.Table.C(v.Name).Accept(context)
+		context.SetDefaultTableName("")
+		updates = append(updates, fmt.Sprintf("%s = %s", target, GetClauseFrom(v.Value).Accept(context)))
+	}
+	if len(updates) == 0 {
+		return sql + " DO NOTHING"
+	}
+	sql += " DO UPDATE SET " + strings.Join(updates, ", ")
+	if upsert.UpdateWhere != nil {
+		context.SetDefaultTableName("")
+		sql += " " + upsert.UpdateWhere.Accept(context)
+	}
+	return sql
 }
 
 // VisitWhere compiles a WHERE clause
 func (c SQLCompiler) VisitWhere(context Context, where WhereClause) string {
 	return fmt.Sprintf("WHERE %s", where.clause.Accept(context))
 }
+
+// VisitWindow compiles the content of a window specification
+func (c SQLCompiler) VisitWindow(context Context, window WindowClause) string {
+	var tokens []string
+	if window.Ref != "" {
+		tokens = append(tokens, context.Compiler().VisitLabel(context, window.Ref))
+	}
+	if len(window.PartitionByClause) != 0 {
+		tokens = append(tokens, "PARTITION BY "+List(window.PartitionByClause...).Accept(context))
+	}
+	if window.OrderByClause != nil {
+		tokens = append(tokens, window.OrderByClause.Accept(context))
+	}
+	if window.Frame != "" {
+		tokens = append(tokens, window.Frame)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// VisitWith compiles a WITH clause
+// Each common table expression query is compiled as an independent
+// statement
+func (c SQLCompiler) VisitWith(context Context, with WithClause) string {
+	defaultTableName := context.DefaultTableName()
+	inSubQuery := context.InSubQuery()
+	defer func() {
+		context.SetDefaultTableName(defaultTableName)
+		context.SetInSubQuery(inSubQuery)
+	}()
+	context.SetInSubQuery(false)
+	c.CheckFeature(context, FeatureCTE)
+
+	sql := "WITH "
+	ctes := []string{}
+	for _, cte := range with.CTEs {
+		if cte.Recursive {
+			sql = "WITH RECURSIVE "
+		}
+		ctes = append(ctes, fmt.Sprintf(
+			"%s AS (%s)",
+			context.Compiler().VisitLabel(context, cte.Name),
+			cte.Query.Accept(context),
+		))
+	}
+	return sql + strings.Join(ctes, ", ")
+}
@@ -23,6 +23,7 @@ type Stmt struct {
 	bindings     []interface{}
 	delimiter    string
 	bindingIndex int
+	errors       []error
 }
 
 // Text is for executing raw sql
@@ -54,6 +55,20 @@ func (s *Stmt) AddBinding(bindings ...interface{}) {
 	}
 }
 
+// AddError records errors that occurred while building the query
+func (s *Stmt) AddError(errs ...error) {
+	s.errors = append(s.errors, errs...)
+}
+
+// Err returns the first error that occurred while building the query, if any
+// The query should not be executed if Err() is not nil
+func (s *Stmt) Err() error {
+	if len(s.errors) > 0 {
+		return s.errors[0]
+	}
+	return nil
+}
+
 // SQLClauses returns all clauses of current query
 func (s *Stmt) SQLClauses() []string {
 	return s.clauses
@@ -2,6 +2,7 @@ package qb
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -24,6 +25,9 @@ func Table(name string, clauses ...TableSQLClause) TableElem {
 				pkeyCols = append(pkeyCols, col)
 			}
 			col.Table = name
+			if _, ok := table.Columns[col.Name]; !ok {
+				table.columnNames = append(table.columnNames, col.Name)
+			}
 			table.Columns[col.Name] = col
 			break
 		case PrimaryKeyConstraint:
@@ -34,24 +38,42 @@ func Table(name string, clauses ...TableSQLClause) TableElem {
 		//		table.ForeignKeyConstraints.FKeys,
 		//		clause.(ForeignKeyConstraints).FKeys...)
 		case ForeignKeyConstraint:
+			fkey := clause.(ForeignKeyConstraint)
+			if fkey.err != nil {
+				table.errors = append(table.errors, fkey.err)
+			}
 			table.ForeignKeyConstraints.FKeys = append(
 				table.ForeignKeyConstraints.FKeys,
-				clause.(ForeignKeyConstraint),
+				fkey,
 			)
 			break
 		case UniqueKeyConstraint:
-			table.UniqueKeyConstraint = clause.(UniqueKeyConstraint).Table(table.Name)
+			table.UniqueKeyConstraints = append(
+				table.UniqueKeyConstraints,
+				clause.(UniqueKeyConstraint).Table(table.Name),
+			)
+			table.UniqueKeyConstraint = table.UniqueKeyConstraints[len(table.UniqueKeyConstraints)-1]
+			break
+		case CheckConstraint:
+			check := clause.(CheckConstraint)
+			check.table = table.Name
+			table.CheckConstraints = append(table.CheckConstraints, check)
 			break
 		case IndexElem:
-			table.Indices = append(table.Indices, clause.(IndexElem))
+			table.Indices = append(table.Indices, clause.(IndexElem).table(name))
 			break
 		}
 	}
 
 	if len(pkeyCols) > 0 && table.PrimaryKeyConstraint.Columns != nil {
-		panic(fmt.Sprintf("Table %s has both 'PrimaryKey()' columns (%#v) and a PrimaryKeyConstraint. Only only should be set", name, pkeyCols))
-	}
-	if len(pkeyCols) > 0 {
+		var pkeyNames []string
+		for _, col := range pkeyCols {
+			pkeyNames = append(pkeyNames, col.Name)
+		}
+		table.errors = append(table.errors, CompileError(
+			"Table %s has both 'PrimaryKey()' columns (%s) and a PrimaryKeyConstraint. Only one should be set",
+			name, strings.Join(pkeyNames, ", ")))
+	} else if len(pkeyCols) > 0 {
 		var pkeyNames []string
 		for _, col := range pkeyCols {
 			pkeyNames = append(pkeyNames, col.Name)
@@ -63,7 +85,7 @@ func Table(name string, clauses ...TableSQLClause) TableElem {
 	for _, name := range table.PrimaryKeyConstraint.Columns {
 		table.Columns[name] = table.Columns[name].PrimaryKey()
 	}
-	if len(table.PrimaryKeyConstraint.Columns) == 1 {
+	if len(table.PrimaryKeyConstraint.Columns) == 1 && table.PrimaryKeyConstraint.name == "" {
 		// Make sure the column will inline the primary key
 		name := table.PrimaryKeyConstraint.Columns[0]
 		table.Columns[name] = table.Columns[name].inlinePrimaryKey()
@@ -78,8 +100,23 @@ type TableElem struct {
 	Columns               map[string]ColumnElem
 	PrimaryKeyConstraint  PrimaryKeyConstraint
 	ForeignKeyConstraints ForeignKeyConstraints
-	UniqueKeyConstraint   UniqueKeyConstraint
+	UniqueKeyConstraints  []UniqueKeyConstraint
+	CheckConstraints      []CheckConstraint
 	Indices               []IndexElem
+
+	// UniqueKeyConstraint is the last unique key constraint of the table
+	// A constraint set there and missing from UniqueKeyConstraints is created
+	// with the table too.
+	//
+	// Deprecated: use UniqueKeyConstraints, a table can have several unique
+	// key constraints
+	UniqueKeyConstraint UniqueKeyConstraint
+
+	// columnNames keeps the column definition order
+	columnNames []string
+	// errors are the errors of the table definition, reported when the table
+	// is compiled
+	errors []error
 }
 
 // DefaultName returns the name of the table
@@ -90,21 +127,52 @@ func (t TableElem) DefaultName() string {
 // All returns all columns of table as a column slice
 func (t TableElem) All() []Clause {
 	cols := []Clause{}
-	for _, v := range t.Columns {
+	for _, v := range t.ColumnList() {
 		cols = append(cols, v)
 	}
 	return cols
 }
 
-// ColumnList columns of the table
+// ColumnList columns of the table, in their definition order
 func (t TableElem) ColumnList() []ColumnElem {
 	cols := []ColumnElem{}
-	for _, v := range t.Columns {
-		cols = append(cols, v)
+	for _, name := range t.ColumnNames() {
+		cols = append(cols, t.Columns[name])
 	}
 	return cols
 }
 
+// ColumnNames returns the column names of the table, in their definition order
+func (t TableElem) ColumnNames() []string {
+	names := []string{}
+	for name := range t.Columns {
+		names = append(names, name)
+	}
+	return t.sortColumnNames(names)
+}
+
+// sortColumnNames sorts column names in a stable order: the table columns in
+// their definition order first, then the other names alphabetically
+func (t TableElem) sortColumnNames(names []string) []string {
+	position := map[string]int{}
+	for i, name := range t.columnNames {
+		position[name] = i
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		pi, iok := position[names[i]]
+		pj, jok := position[names[j]]
+		switch {
+		case iok && jok:
+			return pi < pj
+		case iok != jok:
+			return iok
+		default:
+			return names[i] < names[j]
+		}
+	})
+	return names
+}
+
 // Index appends an IndexElem to current table without giving table name
 func (t TableElem) Index(cols ...string) TableElem {
 	t.Indices = append(t.Indices, Index(t.Name, cols...))
@@ -112,16 +180,23 @@ func (t TableElem) Index(cols ...string) TableElem {
 }
 
 // Create generates create table syntax and returns it as a query struct
-func (t TableElem) Create(dialect Dialect) string {
+// With the IfNotExists option, the creation of the table and of its indexes
+// is skipped if they already exist. The errors of the table definition are
+// only reported by Build
+func (t TableElem) Create(dialect Dialect, options ...DDLOption) string {
 	statement := Statement()
-	statement.AddSQLClause(fmt.Sprintf("CREATE TABLE %s (", dialect.Escape(t.Name)))
+	create := "CREATE TABLE"
+	if hasDDLOption(options, IfNotExists) {
+		create += " IF NOT EXISTS"
+	}
+	statement.AddSQLClause(fmt.Sprintf("%s %s (", create, dialect.Escape(t.Name)))
 
 	colClauses := []string{}
-	for _, col := range t.Columns {
+	for _, col := range t.ColumnList() {
 		colClauses = append(colClauses, fmt.Sprintf("\t%s", col.String(dialect)))
 	}
 
-	if len(t.PrimaryKeyConstraint.Columns) > 1 {
+	if len(t.PrimaryKeyConstraint.Columns) > 1 || t.PrimaryKeyConstraint.name != "" {
 		colClauses = append(colClauses, fmt.Sprintf("\t%s", t.PrimaryKeyConstraint.String(dialect)))
 	}
 
@@ -129,8 +204,12 @@ func (t TableElem) Create(dialect Dialect) string {
 		colClauses = append(colClauses, t.ForeignKeyConstraints.String(dialect))
 	}
 
-	if t.UniqueKeyConstraint.name != "" {
-		colClauses = append(colClauses, fmt.Sprintf("\t%s", t.UniqueKeyConstraint.String(dialect)))
+	for _, unique := range t.uniqueKeys() {
+		colClauses = append(colClauses, fmt.Sprintf("\t%s", unique.String(dialect)))
+	}
+
+	for _, check := range t.CheckConstraints {
+		colClauses = append(colClauses, fmt.Sprintf("\t%s", check.String(dialect)))
 	}
 
 	statement.AddSQLClause(strings.Join(colClauses, ",\n"))
@@ -141,7 +220,7 @@ func (t TableElem) Create(dialect Dialect) string {
 
 	indexSqls := []string{}
 	for _, index := range t.Indices {
-		iSQLClause := index.String(dialect)
+		iSQLClause := index.Create(dialect, options...) + ";"
 		indexSqls = append(indexSqls, iSQLClause)
 	}
 
@@ -152,13 +231,45 @@ func (t TableElem) Create(dialect Dialect) string {
 }
 
 // Build generates a Statement object out of table ddl
+// The errors of the table definition are added to the statement
 func (t TableElem) Build(dialect Dialect) *Stmt {
-	sql := t.Create(dialect)
+	return t.buildCreate(dialect)
+}
+
+// buildCreate generates a Statement object out of table ddl, given the
+// options of the CREATE statements
+func (t TableElem) buildCreate(dialect Dialect, options ...DDLOption) *Stmt {
+	sql := t.Create(dialect, options...)
 	statement := Statement()
 	statement.AddSQLClause(strings.Trim(sql, ";")) // TODO: Remove this ugly hack
+	statement.AddError(t.errors...)
+	for _, index := range t.Indices {
+		statement.AddError(index.build(dialect, options...).errors...)
+	}
+	for _, col := range t.ColumnList() {
+		if col.Options.AutoIncrement && !col.Options.InlinePrimaryKey &&
+			!dialect.Features().Has(FeatureAutoIncrement) {
+			statement.AddError(NotSupportedError(dialect, FeatureAutoIncrement))
+		}
+	}
 	return statement
 }
 
+// uniqueKeys returns the unique key constraints of the table, including the
+// deprecated UniqueKeyConstraint if it is not one of UniqueKeyConstraints
+func (t TableElem) uniqueKeys() []UniqueKeyConstraint {
+	deprecated := t.UniqueKeyConstraint.Table(t.Name)
+	if len(deprecated.cols) == 0 {
+		return t.UniqueKeyConstraints
+	}
+	for _, unique := range t.UniqueKeyConstraints {
+		if unique.name == deprecated.name {
+			return t.UniqueKeyConstraints
+		}
+	}
+	return append(append([]UniqueKeyConstraint{}, t.UniqueKeyConstraints...), deprecated)
+}
+
 // PrimaryCols returns the columns that are primary key to the table
 func (t TableElem) PrimaryCols() []ColumnElem {
 	primaryCols := []ColumnElem{}
@@ -170,15 +281,58 @@ func (t TableElem) PrimaryCols() []ColumnElem {
 }
 
 // Drop generates drop table syntax and returns it as a query struct
-func (t TableElem) Drop(dialect Dialect) string {
+// With the IfExists option, the drop is skipped if the table does not exist.
+// With the Cascade option, the objects depending on the table are dropped
+// too, which is not supported by all the dialects
+func (t TableElem) Drop(dialect Dialect, options ...DDLOption) string {
+	return t.buildDrop(dialect, options...).SQL()
+}
+
+// buildDrop generates a Statement object out of the drop table syntax
+// Using the Cascade option with a dialect that does not support it is an
+// error
+func (t TableElem) buildDrop(dialect Dialect, options ...DDLOption) *Stmt {
+	drop := "DROP TABLE "
+	if hasDDLOption(options, IfExists) {
+		drop += "IF EXISTS "
+	}
+	drop += dialect.Escape(t.Name)
 	stmt := Statement()
-	stmt.AddSQLClause(fmt.Sprintf("DROP TABLE %s", dialect.Escape(t.Name)))
-	return stmt.SQL()
+	if hasDDLOption(options, Cascade) {
+		if dialect.Features().Has(FeatureDropCascade) {
+			drop += " CASCADE"
+		} else {
+			stmt.AddError(NotSupportedError(dialect, FeatureDropCascade))
+		}
+	}
+	stmt.AddSQLClause(drop)
+	return stmt
 }
 
-// C returns the column name given col
+// C returns the column of the table with the given name
+// If the table has no such column, compiling the returned column fails
 func (t TableElem) C(name string) ColumnElem {
-	return t.Columns[name]
+	if col, ok := t.Lookup(name); ok {
+		return col
+	}
+	return ColumnElem{
+		Name:  name,
+		Table: t.Name,
+		err:   CompileError("No such column '%s' in table %s", name, t.Name),
+	}
+}
+
+// Lookup returns the column of the table with the given name, and whether
+// the table has such a column
+func (t TableElem) Lookup(name string) (ColumnElem, bool) {
+	col, ok := t.Columns[name]
+	return col, ok
+}
+
+// Has returns true if the table has a column with the given name
+func (t TableElem) Has(name string) bool {
+	_, ok := t.Columns[name]
+	return ok
 }
 
 // query starters
@@ -212,3 +366,31 @@ func (t TableElem) Select(clauses ...Clause) SelectStmt {
 func (t TableElem) Accept(context Context) string {
 	return context.Compiler().VisitTable(context, t)
 }
+
+// DDLOption is an option of the CREATE and DROP statements generated by the
+// table and index definitions
+type DDLOption int
+
+const (
+	// IfNotExists makes the creation of the existing tables and indexes
+	// skipped
+	IfNotExists DDLOption = iota
+	// IfExists makes the drop of the missing tables skipped
+	IfExists
+	// Cascade makes the drop of a table also drop the objects that depend on
+	// it, such as the foreign keys of other tables
+	Cascade
+	// Concurrently makes the creation and drop of indexes not lock the table
+	// writes. Such statements cannot be executed in a transaction, hence
+	// MetaDataElem.CreateAll and DropAll refuse it
+	Concurrently
+)
+
+func hasDDLOption(options []DDLOption, option DDLOption) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package qb
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,6 +40,25 @@ func (suite *TableTestSuite) TestTableSimpleDrop() {
 	assert.Equal(suite.T(), "DROP TABLE users;", usersTable.Drop(suite.dialect))
 }
 
+func (suite *TableTestSuite) TestTableDDLOptions() {
+	usersTable := Table(
+		"users",
+		Column("id", Varchar().Size(40)),
+		Column("email", Varchar()),
+		Index("users", "email"),
+	)
+
+	assert.Equal(suite.T(),
+		"CREATE TABLE IF NOT EXISTS users (\n\tid VARCHAR(40),\n\temail VARCHAR(255)\n);\n"+
+			"CREATE INDEX IF NOT EXISTS i_users_email ON users(email);",
+		usersTable.Create(suite.dialect, IfNotExists))
+	assert.Equal(suite.T(), "DROP TABLE IF EXISTS users;", usersTable.Drop(suite.dialect, IfExists))
+	assert.Equal(suite.T(), "DROP TABLE IF EXISTS users CASCADE;", usersTable.Drop(suite.dialect, IfExists, Cascade))
+
+	statement := usersTable.buildDrop(restrictedDialect{suite.dialect, 0}, Cascade)
+	assert.Equal(suite.T(), NotSupportedError(suite.dialect, FeatureDropCascade).Error(), statement.Err().Error())
+}
+
 func (suite *TableTestSuite) TestTablePrimaryForeignKey() {
 	usersTable := Table(
 		"users",
@@ -88,13 +108,12 @@ func (suite *TableTestSuite) TestTableCompositePrimaryKey() {
 	ddl := users.Create(suite.dialect)
 	assert.Contains(suite.T(), ddl, "PRIMARY KEY(fname, lname)")
 
-	assert.Panics(suite.T(), func() {
-		Table(
-			"users",
-			Column("id", Varchar().Size(40)).PrimaryKey(),
-			PrimaryKey("id"),
-		)
-	})
+	err := Table(
+		"users",
+		Column("id", Varchar().Size(40)).PrimaryKey(),
+		PrimaryKey("id"),
+	).Build(suite.dialect).Err()
+	assert.Equal(suite.T(), CompileError("Table users has both 'PrimaryKey()' columns (id) and a PrimaryKeyConstraint. Only one should be set"), err)
 }
 
 func (suite *TableTestSuite) TestTableUniqueCompositeUnique() {
@@ -115,6 +134,55 @@ func (suite *TableTestSuite) TestTableUniqueCompositeUnique() {
 	assert.Contains(suite.T(), ddl, ");")
 }
 
+func (suite *TableTestSuite) TestTableConstraints() {
+	age := Column("age", Int())
+	usersTable := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("device_id", Varchar()),
+		age.Check(age.Gte(0)),
+		Column("team_id", Int()),
+		PrimaryKey("id").Name("pk_users"),
+		UniqueKey("email"),
+		UniqueKey("email", "device_id").Name("uniq_device"),
+		Check(age.Lt(150)).Name("realistic_age"),
+		ForeignKey("team_id").References("teams", "id").Name("fk_team"),
+	)
+
+	assert.Equal(suite.T(), strings.Join([]string{
+		"CREATE TABLE users (",
+		"\tid INT,",
+		"\temail VARCHAR(255),",
+		"\tdevice_id VARCHAR(255),",
+		"\tage INT CHECK (age >= 0),",
+		"\tteam_id INT,",
+		"\tCONSTRAINT pk_users PRIMARY KEY(id),",
+		"\tCONSTRAINT fk_team FOREIGN KEY(team_id) REFERENCES teams(id),",
+		"\tCONSTRAINT u_users_email UNIQUE(email),",
+		"\tCONSTRAINT uniq_device UNIQUE(email, device_id),",
+		"\tCONSTRAINT realistic_age CHECK (age < 150)",
+		");",
+	}, "\n"), usersTable.Create(suite.dialect))
+	assert.Equal(suite.T(), []ColumnElem{usersTable.C("id")}, usersTable.PrimaryCols())
+	assert.True(suite.T(), usersTable.C("id").Options.PrimaryKey)
+
+	// the deprecated UniqueKeyConstraint is the last unique key constraint
+	assert.Equal(suite.T(), "uniq_device", usersTable.UniqueKeyConstraint.name)
+	devices := Table("devices", Column("id", Int()), Column("serial", Varchar()))
+	devices.UniqueKeyConstraint = UniqueKey("serial")
+	assert.Equal(suite.T(), strings.Join([]string{
+		"CREATE TABLE devices (",
+		"\tid INT,",
+		"\tserial VARCHAR(255),",
+		"\tCONSTRAINT u_devices_serial UNIQUE(serial)",
+		");",
+	}, "\n"), devices.Create(suite.dialect))
+	assert.Equal(suite.T(), "ALTER TABLE devices DROP CONSTRAINT u_devices_serial;",
+		AlterTable(devices).DropConstraint("u_devices_serial").Build(suite.dialect).SQL())
+	assert.Empty(suite.T(), AlterTable(devices).DropConstraint("u_devices_serial").Altered().UniqueKeyConstraint.cols)
+}
+
 func (suite *TableTestSuite) TestTableIndex() {
 	usersTable := Table(
 		"users",
@@ -129,12 +197,26 @@ func (suite *TableTestSuite) TestTableIndex() {
 	assert.Contains(suite.T(), ddl, "id VARCHAR(40)")
 	assert.Contains(suite.T(), ddl, "email VARCHAR(40) UNIQUE")
 	assert.Contains(suite.T(), ddl, ")")
-	assert.Contains(suite.T(), ddl, "CREATE INDEX i_id ON users(id)")
-	assert.Contains(suite.T(), ddl, "CREATE INDEX i_email ON users(email)")
-	assert.Contains(suite.T(), ddl, "CREATE INDEX i_id_email ON users(id, email);")
+	assert.Contains(suite.T(), ddl, "CREATE INDEX i_users_id ON users(id)")
+	assert.Contains(suite.T(), ddl, "CREATE INDEX i_users_email ON users(email)")
+	assert.Contains(suite.T(), ddl, "CREATE INDEX i_users_id_email ON users(id, email);")
 
 	assert.Equal(suite.T(), ColumnElem{Name: "id", Type: Varchar().Size(40), Table: "users"}, usersTable.C("id"))
-	assert.Zero(suite.T(), usersTable.C("nonExisting"))
+	assert.True(suite.T(), usersTable.Has("id"))
+	assert.False(suite.T(), usersTable.Has("nonExisting"))
+
+	col, ok := usersTable.Lookup("email")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "email", col.Name)
+	col, ok = usersTable.Lookup("nonExisting")
+	assert.False(suite.T(), ok)
+	assert.Zero(suite.T(), col)
+
+	ctx := NewCompilerContext(suite.dialect)
+	assert.Equal(suite.T(), "users.nonExisting", usersTable.C("nonExisting").Accept(ctx))
+	assert.Equal(suite.T(),
+		[]error{CompileError("No such column 'nonExisting' in table users")},
+		ctx.Errors())
 }
 
 func (suite *TableTestSuite) TestTableIndexChain() {
@@ -143,7 +225,7 @@ func (suite *TableTestSuite) TestTableIndexChain() {
 	assert.Contains(suite.T(), ddl, "CREATE TABLE users (")
 	assert.Contains(suite.T(), ddl, "id VARCHAR(40)")
 	assert.Contains(suite.T(), ddl, ");")
-	assert.Contains(suite.T(), ddl, "CREATE INDEX i_id ON users(id);")
+	assert.Contains(suite.T(), ddl, "CREATE INDEX i_users_id ON users(id);")
 }
 
 func (suite *TableTestSuite) TestTableStarters() {
@@ -214,6 +296,24 @@ func (suite *TableTestSuite) TestTableStarters() {
 	assert.Equal(suite.T(), []interface{}{"5a73ef89-cf0a-4c51-ab8c-cc273ebb3a55"}, sel.Bindings())
 }
 
+func (suite *TableTestSuite) TestTableColumnOrder() {
+	usersTable := Table(
+		"users",
+		Column("id", Varchar().Size(40)),
+		Column("email", Varchar()),
+		Column("created_at", Timestamp()),
+		Column("bio", Text()),
+	)
+
+	assert.Equal(suite.T(), []string{"id", "email", "created_at", "bio"}, usersTable.ColumnNames())
+	assert.Equal(suite.T(), usersTable.C("created_at"), usersTable.ColumnList()[2])
+	assert.Equal(suite.T(), []string{"email", "bio", "a", "z"},
+		usersTable.sortColumnNames([]string{"z", "bio", "a", "email"}))
+
+	ddl := usersTable.Create(suite.dialect)
+	assert.Equal(suite.T(), "CREATE TABLE users (\n\tid VARCHAR(40),\n\temail VARCHAR(255),\n\tcreated_at TIMESTAMP,\n\tbio TEXT\n);", ddl)
+}
+
 func TestTableTestSuite(t *testing.T) {
 	suite.Run(t, new(TableTestSuite))
 }
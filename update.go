@@ -6,18 +6,20 @@ package qb
 // Where(qb.Eq("id", 5))
 func Update(table TableElem) UpdateStmt {
 	return UpdateStmt{
-		table:     table,
-		values:    map[string]interface{}{},
-		returning: []ColumnElem{},
+		Table:         table,
+		ValuesMap:     map[string]interface{}{},
+		ReturningCols: []ColumnElem{},
 	}
 }
 
 // UpdateStmt is the base struct for any update statements
 type UpdateStmt struct {
-	table     TableElem
-	values    map[string]interface{}
-	returning []ColumnElem
-	where     *WhereClause
+	Table         TableElem
+	ValuesMap     map[string]interface{}
+	ValuesOrder   []string
+	FromTables    []Selectable
+	WhereClause   *WhereClause
+	ReturningCols []ColumnElem
 }
 
 // Accept implements Clause.Accept
@@ -31,29 +33,60 @@ func (s UpdateStmt) Build(dialect Dialect) *Stmt {
 	statement := Statement()
 	statement.AddSQLClause(s.Accept(context))
 	statement.AddBinding(context.Binds()...)
+	statement.AddError(context.Errors()...)
 
 	return statement
 }
 
 // Values accepts map[string]interface{} and forms the values map of insert statement
+// The keys must be columns of the table, otherwise compiling the statement fails
 func (s UpdateStmt) Values(values map[string]interface{}) UpdateStmt {
 	for k, v := range values {
-		s.values[s.table.C(k).Name] = v
+		s.ValuesMap[k] = v
 	}
 	return s
 }
 
+// OrderedValues sets values of the update statement, and renders the SET
+// clause in the given order
+// Update(usersTable).OrderedValues(Value("email", "al@pacino.com"), Value("name", "Al"))
+func (s UpdateStmt) OrderedValues(values ...ColumnValue) UpdateStmt {
+	for _, v := range values {
+		s.ValuesMap[v.Name] = v.Value
+		s.ValuesOrder = append(s.ValuesOrder, v.Name)
+	}
+	return s
+}
+
+// ColumnNames returns the names of the columns to update, in a stable order:
+// the order given by OrderedValues first, then the table columns in their
+// definition order, then the remaining names alphabetically
+func (s UpdateStmt) ColumnNames() []string {
+	return orderedNames(s.Table, s.ValuesOrder, s.ValuesMap)
+}
+
+// From adds tables the update statement can refer to in its where clause,
+// making it a multi-table update. It is compiled as UPDATE ... FROM on
+// postgres and sqlite (3.33+), as correlated subqueries on older sqlite
+// versions, and as UPDATE ... JOIN ... SET on mysql.
+// The join conditions are given in the where clause
+// Update(users).From(sessions).Where(users.C("id").Eq(sessions.C("user_id")))
+func (s UpdateStmt) From(tables ...Selectable) UpdateStmt {
+	s.FromTables = append(s.FromTables, tables...)
+	return s
+}
+
 // Returning accepts the column names as strings and forms the returning array of insert statement
-// NOTE: Please use it in only postgres dialect, otherwise it'll crash
+// Building the statement fails with ErrUnsupported if the dialect does not support RETURNING
 func (s UpdateStmt) Returning(cols ...ColumnElem) UpdateStmt {
 	for _, c := range cols {
-		s.returning = append(s.returning, c)
+		s.ReturningCols = append(s.ReturningCols, c)
 	}
 	return s
 }
 
 // Where adds a where clause to update statement and returns the update statement
 func (s UpdateStmt) Where(clause Clause) UpdateStmt {
-	s.where = &WhereClause{clause}
+	s.WhereClause = &WhereClause{clause}
 	return s
 }
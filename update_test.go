@@ -1,6 +1,7 @@
 package qb
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -58,6 +59,74 @@ func (suite *UpdateTestSuite) TestUpdateWhereReturning() {
 	}, binds)
 }
 
+func (suite *UpdateTestSuite) TestUpdateColumnOrder() {
+	sql := Update(suite.users).
+		Values(map[string]interface{}{
+			"email": "robert@de.niro",
+			"id":    2,
+		}).Accept(suite.ctx)
+	assert.Equal(suite.T(), "UPDATE users\nSET id = ?, email = ?", sql)
+	assert.Equal(suite.T(), []interface{}{2, "robert@de.niro"}, suite.ctx.Binds())
+
+	ctx := NewCompilerContext(suite.dialect)
+	sql = Update(suite.users).
+		OrderedValues(Value("email", "robert@de.niro"), Value("id", 2)).
+		Accept(ctx)
+	assert.Equal(suite.T(), "UPDATE users\nSET email = ?, id = ?", sql)
+	assert.Equal(suite.T(), []interface{}{"robert@de.niro", 2}, ctx.Binds())
+}
+
+func (suite *UpdateTestSuite) TestUpdateFrom() {
+	sessions := Table(
+		"sessions",
+		Column("id", BigInt()),
+		Column("user_id", BigInt()),
+		Column("email", Varchar()),
+	)
+
+	sql := Update(suite.users).
+		Values(map[string]interface{}{"email": "robert@de.niro"}).
+		From(sessions).
+		Where(And(suite.users.C("id").Eq(sessions.C("user_id")), sessions.C("id").Eq(5))).
+		Returning(suite.users.C("id")).
+		Accept(suite.ctx)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"UPDATE users",
+		"SET email = ?",
+		"FROM sessions",
+		"WHERE (users.id = sessions.user_id AND sessions.id = ?)",
+		"RETURNING id",
+	}, "\n"), sql)
+	assert.Equal(suite.T(), []interface{}{"robert@de.niro", 5}, suite.ctx.Binds())
+}
+
+func (suite *UpdateTestSuite) TestUpdateExpressions() {
+	sql := Update(suite.users).
+		OrderedValues(
+			Value("id", suite.users.C("id").Add(1)),
+			Value("email", SQLText("CURRENT_USER")),
+		).
+		Where(suite.users.C("id").Eq(5)).
+		Accept(suite.ctx)
+	assert.Equal(suite.T(), "UPDATE users\nSET id = id + ?, email = CURRENT_USER\nWHERE id = ?", sql)
+	assert.Equal(suite.T(), []interface{}{1, 5}, suite.ctx.Binds())
+
+	ctx := NewCompilerContext(suite.dialect)
+	sql = Update(suite.users).
+		Values(map[string]interface{}{"email": nil}).
+		Accept(ctx)
+	assert.Equal(suite.T(), "UPDATE users\nSET email = ?", sql)
+	assert.Equal(suite.T(), []interface{}{nil}, ctx.Binds())
+}
+
+func (suite *UpdateTestSuite) TestUpdateUnknownColumn() {
+	stmt := Update(suite.users).
+		Values(map[string]interface{}{"emial": "robert@de.niro"}).
+		Build(suite.dialect)
+
+	assert.Equal(suite.T(), CompileError("No such column 'emial' in table users"), stmt.Err())
+}
+
 func TestUpdateTestSuite(t *testing.T) {
 	suite.Run(t, new(UpdateTestSuite))
 }
@@ -11,12 +11,20 @@ func Upsert(table TableElem) UpsertStmt {
 
 // UpsertStmt is the base struct for any insert ... on conflict/duplicate key ... update ... statements
 type UpsertStmt struct {
-	Table         TableElem
-	ValuesMap     map[string]interface{}
-	ReturningCols []ColumnElem
+	Table              TableElem
+	ValuesMap          map[string]interface{}
+	ValuesOrder        []string
+	ReturningCols      []ColumnElem
+	ConflictCols       []ColumnElem
+	ConflictConstraint string
+	ConflictWhere      *WhereClause
+	UpdateValues       []ColumnValue
+	UpdateWhere        *WhereClause
+	IgnoreConflict     bool
 }
 
 // Values accepts map[string]interface{} and forms the values map of insert statement
+// The keys must be columns of the table, otherwise compiling the statement fails
 func (s UpsertStmt) Values(values map[string]interface{}) UpsertStmt {
 	for k, v := range values {
 		s.ValuesMap[k] = v
@@ -24,6 +32,116 @@ func (s UpsertStmt) Values(values map[string]interface{}) UpsertStmt {
 	return s
 }
 
+// OrderedValues sets values of the upsert statement, and renders the columns
+// in the given order
+func (s UpsertStmt) OrderedValues(values ...ColumnValue) UpsertStmt {
+	for _, v := range values {
+		s.ValuesMap[v.Name] = v.Value
+		s.ValuesOrder = append(s.ValuesOrder, v.Name)
+	}
+	return s
+}
+
+// ColumnNames returns the names of the columns to insert, in a stable order:
+// the order given by OrderedValues first, then the table columns in their
+// definition order, then the remaining names alphabetically
+func (s UpsertStmt) ColumnNames() []string {
+	return orderedNames(s.Table, s.ValuesOrder, s.ValuesMap)
+}
+
+// OnConflict sets the conflict target of the statement to the given columns,
+// which must be covered by a unique index or constraint. It defaults to the
+// primary key of the table.
+// NOTE: mysql ignores the conflict target, any unique key being checked
+func (s UpsertStmt) OnConflict(cols ...ColumnElem) UpsertStmt {
+	s.ConflictCols = append(s.ConflictCols, cols...)
+	return s
+}
+
+// OnConstraint sets the conflict target of the statement to a named unique
+// constraint
+// NOTE: Please use it in only postgres dialect
+func (s UpsertStmt) OnConstraint(name string) UpsertStmt {
+	s.ConflictConstraint = name
+	return s
+}
+
+// OnConflictWhere sets the predicate of the partial unique index used as
+// conflict target
+func (s UpsertStmt) OnConflictWhere(clauses ...Clause) UpsertStmt {
+	where := Where(clauses...)
+	s.ConflictWhere = &where
+	return s
+}
+
+// DoUpdate sets the columns to update on conflict and their values, which
+// can be expressions. Excluded(col) refers to the value proposed for
+// insertion.
+// By default, all the inserted columns but the conflict target and the
+// primary key are updated with the proposed values
+// Upsert(users).Values(...).DoUpdate(Value("logins", users.C("logins").Add(1)))
+func (s UpsertStmt) DoUpdate(values ...ColumnValue) UpsertStmt {
+	s.UpdateValues = append(s.UpdateValues, values...)
+	return s
+}
+
+// DoUpdateColumns sets the columns to update on conflict with the values
+// proposed for insertion
+func (s UpsertStmt) DoUpdateColumns(cols ...ColumnElem) UpsertStmt {
+	for _, col := range cols {
+		s.UpdateValues = append(s.UpdateValues, Value(col.Name, Excluded(col)))
+	}
+	return s
+}
+
+// DoUpdateWhere restricts the conflicting rows that are updated
+func (s UpsertStmt) DoUpdateWhere(clauses ...Clause) UpsertStmt {
+	where := Where(clauses...)
+	s.UpdateWhere = &where
+	return s
+}
+
+// DoNothing makes the statement leave the conflicting rows untouched
+func (s UpsertStmt) DoNothing() UpsertStmt {
+	s.IgnoreConflict = true
+	return s
+}
+
+// ConflictTarget returns the columns of the conflict target, which are the
+// primary key columns if none was given
+func (s UpsertStmt) ConflictTarget() []ColumnElem {
+	if len(s.ConflictCols) > 0 {
+		return s.ConflictCols
+	}
+	return s.Table.PrimaryCols()
+}
+
+// UpdateSet returns the columns to update on conflict and their values
+// If none was given, all the inserted columns but the conflict target and
+// the primary key are updated with the proposed values
+func (s UpsertStmt) UpdateSet() []ColumnValue {
+	if len(s.UpdateValues) > 0 {
+		return s.UpdateValues
+	}
+
+	target := map[string]bool{}
+	if s.ConflictConstraint == "" {
+		for _, col := range s.ConflictTarget() {
+			target[col.Name] = true
+		}
+	}
+	for _, col := range s.Table.PrimaryCols() {
+		target[col.Name] = true
+	}
+	values := []ColumnValue{}
+	for _, name := range s.ColumnNames() {
+		if !target[name] {
+			values = append(values, Value(name, Excluded(s.Table.C(name))))
+		}
+	}
+	return values
+}
+
 // Returning accepts the column names as strings and forms the returning array of insert statement
 // NOTE: Please use it in only postgres dialect, otherwise it'll crash
 func (s UpsertStmt) Returning(cols ...ColumnElem) UpsertStmt {
@@ -44,6 +162,24 @@ func (s UpsertStmt) Build(dialect Dialect) *Stmt {
 	statement := Statement()
 	statement.AddSQLClause(s.Accept(context))
 	statement.AddBinding(context.Binds()...)
+	statement.AddError(context.Errors()...)
 
 	return statement
 }
+
+// Excluded refers to the value of a column proposed for insertion by an
+// upsert statement, in its update values
+// It is compiled as EXCLUDED.<column>, or VALUES(<column>) on mysql
+func Excluded(column ColumnElem) ExcludedClause {
+	return ExcludedClause{Column: column}
+}
+
+// ExcludedClause is a reference to the value proposed for insertion
+type ExcludedClause struct {
+	Column ColumnElem
+}
+
+// Accept calls the compiler VisitExcluded method
+func (c ExcludedClause) Accept(context Context) string {
+	return context.Compiler().VisitExcluded(context, c)
+}
@@ -25,10 +25,58 @@ func TestUpsert(t *testing.T) {
 		"created_at": now,
 	})
 
-	assert.Panics(t, func() {
-		ups.Build(def)
-	})
+	assert.Equal(t, ErrUnsupported, ups.Build(def).Err().(Error).Code)
 
 	ups = ups.Returning(users.C("email"))
 	assert.Equal(t, []ColumnElem{users.C("email")}, ups.ReturningCols)
 }
+
+func TestUpsertColumnNames(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Varchar().Size(36)),
+		Column("email", Varchar()).Unique(),
+		Column("created_at", Timestamp()).NotNull(),
+		PrimaryKey("id"),
+	)
+
+	ups := Upsert(users).Values(map[string]interface{}{
+		"created_at": "now",
+		"email":      "al@pacino.com",
+		"id":         "9883cf81-3b56-4151-ae4e-3903c5bc436d",
+	})
+	assert.Equal(t, []string{"id", "email", "created_at"}, ups.ColumnNames())
+
+	ups = Upsert(users).OrderedValues(Value("email", "al@pacino.com"), Value("id", "1"))
+	assert.Equal(t, []string{"email", "id"}, ups.ColumnNames())
+}
+
+func TestUpsertUpdateSet(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()).Unique(),
+		Column("logins", Int()),
+		PrimaryKey("id"),
+	)
+
+	ups := Upsert(users).Values(map[string]interface{}{"id": 1, "email": "al@pacino.com", "logins": 1})
+	assert.Equal(t, []ColumnElem{users.C("id")}, ups.ConflictTarget())
+	assert.Equal(t, []ColumnValue{
+		Value("email", Excluded(users.C("email"))),
+		Value("logins", Excluded(users.C("logins"))),
+	}, ups.UpdateSet())
+
+	// the primary key of the existing row is left untouched
+	ups = ups.OnConflict(users.C("email"))
+	assert.Equal(t, []ColumnElem{users.C("email")}, ups.ConflictTarget())
+	assert.Equal(t, []ColumnValue{
+		Value("logins", Excluded(users.C("logins"))),
+	}, ups.UpdateSet())
+
+	ups = ups.DoUpdateColumns(users.C("logins"))
+	assert.Equal(t, []ColumnValue{Value("logins", Excluded(users.C("logins")))}, ups.UpdateSet())
+
+	ctx := NewCompilerContext(NewDefaultDialect())
+	assert.Equal(t, "EXCLUDED.logins", Excluded(users.C("logins")).Accept(ctx))
+}
@@ -0,0 +1,39 @@
+package qb
+
+// ColumnValue is a column name and value pair
+// A list of ColumnValue gives the values of a statement in a fixed order
+type ColumnValue struct {
+	Name  string
+	Value interface{}
+}
+
+// Value returns a ColumnValue given the column name and the value
+// Insert(usersTable).OrderedValues(Value("id", 1), Value("email", "al@pacino.com"))
+func Value(name string, value interface{}) ColumnValue {
+	return ColumnValue{
+		Name:  name,
+		Value: value,
+	}
+}
+
+// orderedNames returns the names of values in a stable order: the names in
+// order first, then the table columns in their definition order, then the
+// remaining names alphabetically
+func orderedNames(table TableElem, order []string, values map[string]interface{}) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, name := range order {
+		if _, ok := values[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+
+	rest := []string{}
+	for name := range values {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	return append(names, table.sortColumnNames(rest)...)
+}
@@ -21,6 +21,11 @@ func (c WhereClause) Accept(context Context) string {
 	return context.Compiler().VisitWhere(context, c)
 }
 
+// Clause returns the condition of the where clause
+func (c WhereClause) Clause() Clause {
+	return c.clause
+}
+
 // And combine the current clause and the new ones with a And()
 func (c WhereClause) And(clauses ...Clause) WhereClause {
 	clauses = append([]Clause{c.clause}, clauses...)
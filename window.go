@@ -0,0 +1,139 @@
+package qb
+
+import "fmt"
+
+// These are the frame boundaries that can be passed to WindowClause.Rows()
+// and WindowClause.Range()
+const (
+	UnboundedPreceding = "UNBOUNDED PRECEDING"
+	CurrentRow         = "CURRENT ROW"
+	UnboundedFollowing = "UNBOUNDED FOLLOWING"
+)
+
+// Preceding generates a "<offset> PRECEDING" frame boundary
+func Preceding(offset int) string {
+	return fmt.Sprintf("%d PRECEDING", offset)
+}
+
+// Following generates a "<offset> FOLLOWING" frame boundary
+func Following(offset int) string {
+	return fmt.Sprintf("%d FOLLOWING", offset)
+}
+
+// Window generates an empty window specification
+// Window().PartitionBy(usersTable.C("group_id")).OrderBy(usersTable.C("id"))
+func Window() WindowClause {
+	return WindowClause{}
+}
+
+// WindowRef generates a window specification based on a named window
+// defined with SelectStmt.Window()
+func WindowRef(name string) WindowClause {
+	return WindowClause{Ref: name}
+}
+
+// WindowClause is a window specification, as used by OVER and WINDOW clauses
+type WindowClause struct {
+	Name              string
+	Ref               string
+	PartitionByClause []Clause
+	OrderByClause     *OrderByClause
+	Frame             string
+}
+
+// PartitionBy sets the partition by clause of the window
+func (w WindowClause) PartitionBy(clauses ...Clause) WindowClause {
+	w.PartitionByClause = clauses
+	return w
+}
+
+// OrderBy sets the order by clause of the window
+func (w WindowClause) OrderBy(columns ...ColumnElem) WindowClause {
+	w.OrderByClause = &OrderByClause{columns, "ASC"}
+	return w
+}
+
+// Asc sets the t type of current order by clause
+// NOTE: Please use it after calling OrderBy()
+func (w WindowClause) Asc() WindowClause {
+	w.OrderByClause.t = "ASC"
+	return w
+}
+
+// Desc sets the t type of current order by clause
+// NOTE: Please use it after calling OrderBy()
+func (w WindowClause) Desc() WindowClause {
+	w.OrderByClause.t = "DESC"
+	return w
+}
+
+// Rows sets a "ROWS BETWEEN <start> AND <end>" frame clause
+func (w WindowClause) Rows(start string, end string) WindowClause {
+	w.Frame = fmt.Sprintf("ROWS BETWEEN %s AND %s", start, end)
+	return w
+}
+
+// Range sets a "RANGE BETWEEN <start> AND <end>" frame clause
+func (w WindowClause) Range(start string, end string) WindowClause {
+	w.Frame = fmt.Sprintf("RANGE BETWEEN %s AND %s", start, end)
+	return w
+}
+
+// Accept calls the compiler VisitWindow method
+func (w WindowClause) Accept(context Context) string {
+	return context.Compiler().VisitWindow(context, w)
+}
+
+// isRef returns true if the window only refers to a named window
+func (w WindowClause) isRef() bool {
+	return w.Ref != "" &&
+		len(w.PartitionByClause) == 0 &&
+		w.OrderByClause == nil &&
+		w.Frame == ""
+}
+
+// OverClause is a window function call: <function> OVER <window>
+type OverClause struct {
+	Function AggregateClause
+	Window   WindowClause
+}
+
+// Accept calls the compiler VisitOver method
+func (c OverClause) Accept(context Context) string {
+	return context.Compiler().VisitOver(context, c)
+}
+
+// Label wraps the Label(name string, clause Clause)
+func (c OverClause) Label(name string) LabelledClause {
+	return Label(name, c)
+}
+
+// RowNumber generates a ROW_NUMBER() window function
+func RowNumber() AggregateClause {
+	return Aggregate("ROW_NUMBER", nil)
+}
+
+// Rank generates a RANK() window function
+func Rank() AggregateClause {
+	return Aggregate("RANK", nil)
+}
+
+// DenseRank generates a DENSE_RANK() window function
+func DenseRank() AggregateClause {
+	return Aggregate("DENSE_RANK", nil)
+}
+
+// Lag generates a LAG(clause, offset) window function
+func Lag(clause Clause, offset int) AggregateClause {
+	return Aggregate("LAG", List(clause, SQLText(fmt.Sprint(offset))))
+}
+
+// Lead generates a LEAD(clause, offset) window function
+func Lead(clause Clause, offset int) AggregateClause {
+	return Aggregate("LEAD", List(clause, SQLText(fmt.Sprint(offset))))
+}
+
+// FirstValue generates a FIRST_VALUE(clause) window function
+func FirstValue(clause Clause) AggregateClause {
+	return Aggregate("FIRST_VALUE", clause)
+}
@@ -0,0 +1,92 @@
+package qb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowFunctions(t *testing.T) {
+	col := Column("id", Int())
+	assert.Equal(t, Aggregate("ROW_NUMBER", nil), RowNumber())
+	assert.Equal(t, Aggregate("RANK", nil), Rank())
+	assert.Equal(t, Aggregate("DENSE_RANK", nil), DenseRank())
+	assert.Equal(t, Aggregate("LAG", List(col, SQLText("1"))), Lag(col, 1))
+	assert.Equal(t, Aggregate("LEAD", List(col, SQLText("2"))), Lead(col, 2))
+	assert.Equal(t, Aggregate("FIRST_VALUE", col), FirstValue(col))
+}
+
+func TestOver(t *testing.T) {
+	scores := Table(
+		"scores",
+		Column("id", Int()),
+		Column("player", Varchar()),
+		Column("points", Int()),
+	)
+
+	for _, tt := range []struct {
+		clause Clause
+		expect string
+	}{
+		{RowNumber().Over(Window()), "ROW_NUMBER() OVER ()"},
+		{
+			Rank().Over(Window().OrderBy(scores.C("points")).Desc()),
+			"RANK() OVER (ORDER BY scores.points DESC)",
+		},
+		{
+			DenseRank().Over(Window().PartitionBy(scores.C("player")).OrderBy(scores.C("points")).Asc()),
+			"DENSE_RANK() OVER (PARTITION BY scores.player ORDER BY scores.points ASC)",
+		},
+		{
+			Lag(scores.C("points"), 1).Over(Window().OrderBy(scores.C("id"))),
+			"LAG(scores.points, 1) OVER (ORDER BY scores.id ASC)",
+		},
+		{
+			Lead(scores.C("points"), 2).Over(WindowRef("w")),
+			"LEAD(scores.points, 2) OVER w",
+		},
+		{
+			FirstValue(scores.C("points")).Over(WindowRef("w").Rows(UnboundedPreceding, CurrentRow)),
+			"FIRST_VALUE(scores.points) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)",
+		},
+		{
+			Sum(scores.C("points")).Over(Window().OrderBy(scores.C("id")).Rows(Preceding(2), Following(1))),
+			"SUM(scores.points) OVER (ORDER BY scores.id ASC ROWS BETWEEN 2 PRECEDING AND 1 FOLLOWING)",
+		},
+		{
+			Avg(scores.C("points")).Over(Window().OrderBy(scores.C("id")).Range(UnboundedPreceding, UnboundedFollowing)),
+			"AVG(scores.points) OVER (ORDER BY scores.id ASC RANGE BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)",
+		},
+	} {
+		ctx := NewCompilerContext(NewDefaultDialect())
+		assert.Equal(t, tt.expect, tt.clause.Accept(ctx))
+	}
+}
+
+func TestSelectWindow(t *testing.T) {
+	scores := Table(
+		"scores",
+		Column("id", Int()),
+		Column("player", Varchar()),
+		Column("points", Int()),
+	)
+
+	sel := Select(
+		scores.C("player"),
+		RowNumber().Over(WindowRef("w")),
+		Sum(scores.C("points")).Over(WindowRef("w").Rows(UnboundedPreceding, CurrentRow)),
+	).
+		From(scores).
+		Window("w", Window().PartitionBy(scores.C("player")).OrderBy(scores.C("id"))).
+		Window("w2", Window().OrderBy(scores.C("points")).Desc()).
+		OrderBy(scores.C("player"))
+
+	ctx := NewCompilerContext(NewDefaultDialect())
+	assert.Equal(t, strings.Join([]string{
+		"SELECT player, ROW_NUMBER() OVER w, SUM(points) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)",
+		"FROM scores",
+		"WINDOW w AS (PARTITION BY player ORDER BY id ASC), w2 AS (ORDER BY points DESC)",
+		"ORDER BY player ASC",
+	}, "\n"), sel.Accept(ctx))
+}